@@ -0,0 +1,189 @@
+package infuzu
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	constants "github.com/infuzu/infuzu-go-sdk/infuzu/constants"
+	requests "github.com/infuzu/infuzu-go-sdk/infuzu/requests"
+	utils "github.com/infuzu/infuzu-go-sdk/infuzu/utils"
+	"net/http"
+	"time"
+)
+
+var (
+	ErrNotFound     = errors.New("infuzu/access/access.go access profile not found")
+	ErrForbidden    = errors.New("infuzu/access/access.go access to the profile is forbidden")
+	ErrUnauthorized = errors.New("infuzu/access/access.go access service rejected this service's credentials")
+)
+
+type Permissions []string
+
+func (p Permissions) Has(permission string) bool {
+	for _, granted := range p {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+type UserAccessProfile struct {
+	UserID      string      `json:"user_id"`
+	Roles       []string    `json:"roles,omitempty"`
+	Permissions Permissions `json:"permissions"`
+	IsAdmin     bool        `json:"is_admin,omitempty"`
+}
+
+func (p *UserAccessProfile) HasPermission(permission string) bool {
+	return p != nil && (p.IsAdmin || p.Permissions.Has(permission))
+}
+
+type ObjectAccessProfile struct {
+	UserID      string      `json:"user_id"`
+	ObjectType  string      `json:"object_type"`
+	Permissions Permissions `json:"permissions"`
+	ObjectIDs   []string    `json:"object_ids,omitempty"`
+}
+
+func (p *ObjectAccessProfile) HasPermission(permission string) bool {
+	return p != nil && p.Permissions.Has(permission)
+}
+
+type InstanceAccessProfile struct {
+	ID          string      `json:"id,omitempty"`
+	UserID      string      `json:"user_id"`
+	ObjectType  string      `json:"object_type"`
+	ObjectID    string      `json:"object_id"`
+	Permissions Permissions `json:"permissions"`
+	CreatedAt   *time.Time  `json:"created_at,omitempty"`
+}
+
+func (p *InstanceAccessProfile) HasPermission(permission string) bool {
+	return p != nil && p.Permissions.Has(permission)
+}
+
+type Client struct {
+	Session *requests.SignatureSession
+	BaseURL string
+}
+
+func NewClient(session *requests.SignatureSession) *Client {
+	if session == nil {
+		session = requests.SignedClient
+	}
+	return &Client{Session: session}
+}
+
+var DefaultClient = NewClient(nil)
+
+func (c *Client) baseURL() string {
+	if c.BaseURL != "" {
+		return c.BaseURL
+	}
+	return constants.AccessBaseUrl()
+}
+
+func (c *Client) RetrieveUserAccessProfile(ctx context.Context, userID string) (*UserAccessProfile, error) {
+	var profile UserAccessProfile
+	err := c.do(
+		ctx, "GET", constants.AccessRetrieveUserAccessProfileEndpoint(),
+		map[string]interface{}{"user_id": userID}, nil, &profile,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+func (c *Client) RetrieveObjectAccessProfile(
+	ctx context.Context, userID string, objectType string,
+) (*ObjectAccessProfile, error) {
+	var profile ObjectAccessProfile
+	err := c.do(
+		ctx, "GET", constants.AccessRetrieveObjectAccessProfileEndpoint(),
+		map[string]interface{}{"user_id": userID, "object_type": objectType}, nil, &profile,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+func (c *Client) CreateInstanceAccessProfile(
+	ctx context.Context, profile *InstanceAccessProfile,
+) (*InstanceAccessProfile, error) {
+	if profile == nil {
+		return nil, errors.New("infuzu/access/access.go instance access profile cannot be nil")
+	}
+	var created InstanceAccessProfile
+	err := c.do(ctx, "POST", constants.AccessCreateInstanceAccessProfileEndpoint(), nil, profile, &created)
+	if err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+func (c *Client) RetrieveInstanceAccessProfile(ctx context.Context, instanceID string) (*InstanceAccessProfile, error) {
+	var profile InstanceAccessProfile
+	err := c.do(
+		ctx, "GET", constants.AccessRetrieveInstanceAccessProfileEndpoint(),
+		map[string]interface{}{"instance_id": instanceID}, nil, &profile,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+func (c *Client) DeleteInstanceAccessProfile(ctx context.Context, instanceID string) error {
+	return c.do(
+		ctx, "DELETE", constants.AccessDeleteInstanceAccessProfileEndpoint(),
+		map[string]interface{}{"instance_id": instanceID}, nil, nil,
+	)
+}
+
+func (c *Client) do(
+	ctx context.Context,
+	method string,
+	endpoint string,
+	params map[string]interface{},
+	body interface{},
+	result interface{},
+) error {
+	url, err := utils.BuildURL(c.baseURL(), endpoint, params)
+	if err != nil {
+		return err
+	}
+	var statusCode int
+	statusCode, err = c.Session.RequestJSON(ctx, method, url, body, result)
+	switch statusCode {
+	case http.StatusNotFound:
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	case http.StatusUnauthorized:
+		return fmt.Errorf("%w: %w", ErrUnauthorized, err)
+	case http.StatusForbidden:
+		return fmt.Errorf("%w: %w", ErrForbidden, err)
+	}
+	return err
+}
+
+func RetrieveUserAccessProfile(ctx context.Context, userID string) (*UserAccessProfile, error) {
+	return DefaultClient.RetrieveUserAccessProfile(ctx, userID)
+}
+
+func RetrieveObjectAccessProfile(ctx context.Context, userID string, objectType string) (*ObjectAccessProfile, error) {
+	return DefaultClient.RetrieveObjectAccessProfile(ctx, userID, objectType)
+}
+
+func CreateInstanceAccessProfile(ctx context.Context, profile *InstanceAccessProfile) (*InstanceAccessProfile, error) {
+	return DefaultClient.CreateInstanceAccessProfile(ctx, profile)
+}
+
+func RetrieveInstanceAccessProfile(ctx context.Context, instanceID string) (*InstanceAccessProfile, error) {
+	return DefaultClient.RetrieveInstanceAccessProfile(ctx, instanceID)
+}
+
+func DeleteInstanceAccessProfile(ctx context.Context, instanceID string) error {
+	return DefaultClient.DeleteInstanceAccessProfile(ctx, instanceID)
+}
@@ -0,0 +1,99 @@
+package infuzu
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	requeststest "github.com/infuzu/infuzu-go-sdk/infuzu/requests/requeststest"
+	utils "github.com/infuzu/infuzu-go-sdk/infuzu/utils"
+	"net/http"
+	"testing"
+)
+
+func newAccessService(t *testing.T) (*requeststest.Stub, *Client) {
+	t.Helper()
+	stub := requeststest.NewStub()
+	server := requeststest.NewServer(stub)
+	t.Cleanup(server.Close)
+	client := NewClient(server.Session())
+	client.BaseURL = server.BaseURL()
+	return stub, client
+}
+
+func TestInstanceAccessProfileLifecycle(t *testing.T) {
+	service, client := newAccessService(t)
+	ctx := context.Background()
+	created := InstanceAccessProfile{
+		ID: "instance-1", UserID: "user-1", ObjectType: "document", ObjectID: "doc-1", Permissions: Permissions{"edit"},
+	}
+	service.Set("/access/instance-access-profile/", created, 0)
+	service.Set("/access/instance-access-profile/instance-1/", created, 0)
+
+	profile, err := client.CreateInstanceAccessProfile(ctx, &InstanceAccessProfile{
+		UserID: "user-1", ObjectType: "document", ObjectID: "doc-1", Permissions: Permissions{"edit"},
+	})
+	if err != nil || profile.ID != "instance-1" {
+		t.Fatalf("unexpected created profile %+v, %v", profile, err)
+	}
+	if profile, err = client.RetrieveInstanceAccessProfile(ctx, "instance-1"); err != nil || !profile.HasPermission("edit") {
+		t.Fatalf("unexpected retrieved profile %+v, %v", profile, err)
+	}
+	if err = client.DeleteInstanceAccessProfile(ctx, "instance-1"); err != nil {
+		t.Fatal(err)
+	}
+	for _, request := range []string{
+		"POST /access/instance-access-profile/",
+		"GET /access/instance-access-profile/instance-1/",
+		"DELETE /access/instance-access-profile/instance-1/",
+	} {
+		if service.Count(request) != 1 {
+			t.Fatalf("expected one %s request", request)
+		}
+	}
+
+	if _, err = client.CreateInstanceAccessProfile(ctx, nil); err == nil {
+		t.Fatal("a nil profile was sent")
+	}
+	if _, err = client.RetrieveInstanceAccessProfile(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestAccessClientClassifiesErrors(t *testing.T) {
+	service, client := newAccessService(t)
+	ctx := context.Background()
+	service.Set("/access/user-access-profile/unauthorized/", nil, http.StatusUnauthorized)
+	service.Set("/access/user-access-profile/forbidden/", nil, http.StatusForbidden)
+	service.Set("/access/user-access-profile/broken/", nil, http.StatusInternalServerError)
+
+	for userID, expected := range map[string]error{
+		"missing":      ErrNotFound,
+		"unauthorized": ErrUnauthorized,
+		"forbidden":    ErrForbidden,
+		"..":           utils.ErrInvalidURLParameter,
+	} {
+		if _, err := client.RetrieveUserAccessProfile(ctx, userID); !errors.Is(err, expected) {
+			t.Fatalf("%s: expected %v, got %v", userID, expected, err)
+		}
+	}
+	_, err := client.RetrieveUserAccessProfile(ctx, "broken")
+	if err == nil || errors.Is(err, ErrNotFound) || errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected an unclassified server error, got %v", err)
+	}
+}
+
+func TestObjectAccessProfileEndpointIsReadLazily(t *testing.T) {
+	service, client := newAccessService(t)
+	t.Setenv("ACCESS_RETRIEVE_OBJECT_ACCESS_PROFILE_ENDPOINT", "v2/objects/<str:object_type>/users/<str:user_id>/")
+	expected := ObjectAccessProfile{UserID: "user-1", ObjectType: "document", Permissions: Permissions{"read"}}
+	service.Set("/v2/objects/document/users/user-1/", expected, 0)
+
+	profile, err := client.RetrieveObjectAccessProfile(context.Background(), "user-1", "document")
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded, _ := json.Marshal(profile)
+	if want, _ := json.Marshal(expected); string(encoded) != string(want) {
+		t.Fatalf("expected %s, got %s", want, encoded)
+	}
+}
@@ -0,0 +1,94 @@
+package infuzu
+
+import (
+	"context"
+	utils "github.com/infuzu/infuzu-go-sdk/infuzu/utils"
+	"time"
+)
+
+type objectProfileKey struct {
+	userID     string
+	objectType string
+}
+
+type Authorizer struct {
+	Client         *Client
+	userProfiles   *utils.Cache[string, *UserAccessProfile]
+	objectProfiles *utils.Cache[objectProfileKey, *ObjectAccessProfile]
+}
+
+func NewAuthorizer(client *Client, expiryTime time.Duration, maxSize int) *Authorizer {
+	if client == nil {
+		client = DefaultClient
+	}
+	a := &Authorizer{Client: client}
+	negative := utils.NegativePolicy{
+		ExpiryTime: expiryTime,
+		IsNegative: IsDenial,
+	}
+	a.userProfiles = utils.NewCacheWithOptions(
+		func(ctx context.Context, userID string) (*UserAccessProfile, error) {
+			return a.Client.RetrieveUserAccessProfile(ctx, userID)
+		},
+		utils.CacheOptions[string, *UserAccessProfile]{
+			ExpiryTime: expiryTime,
+			MaxSize:    maxSize,
+			Negative:   negative,
+		},
+	)
+	a.objectProfiles = utils.NewCacheWithOptions(
+		func(ctx context.Context, key objectProfileKey) (*ObjectAccessProfile, error) {
+			return a.Client.RetrieveObjectAccessProfile(ctx, key.userID, key.objectType)
+		},
+		utils.CacheOptions[objectProfileKey, *ObjectAccessProfile]{
+			ExpiryTime: expiryTime,
+			MaxSize:    maxSize,
+			Negative:   negative,
+		},
+	)
+	return a
+}
+
+var DefaultAuthorizer = NewAuthorizer(nil, 60*time.Second, 1000)
+
+func (a *Authorizer) UserAccessProfile(ctx context.Context, userID string) (*UserAccessProfile, error) {
+	return a.userProfiles.Get(ctx, userID)
+}
+
+func (a *Authorizer) ObjectAccessProfile(
+	ctx context.Context, userID string, objectType string,
+) (*ObjectAccessProfile, error) {
+	return a.objectProfiles.Get(ctx, objectProfileKey{userID: userID, objectType: objectType})
+}
+
+func (a *Authorizer) UserHasPermission(ctx context.Context, userID string, permission string) (bool, error) {
+	profile, err := a.UserAccessProfile(ctx, userID)
+	if IsDenial(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return profile.HasPermission(permission), nil
+}
+
+func (a *Authorizer) ObjectHasPermission(
+	ctx context.Context, userID string, objectType string, permission string,
+) (bool, error) {
+	profile, err := a.ObjectAccessProfile(ctx, userID, objectType)
+	if IsDenial(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return profile.HasPermission(permission), nil
+}
+
+func (a *Authorizer) Invalidate(userID string) {
+	a.userProfiles.Remove(userID)
+}
+
+func (a *Authorizer) InvalidateObject(userID string, objectType string) {
+	a.objectProfiles.Remove(objectProfileKey{userID: userID, objectType: objectType})
+}
@@ -0,0 +1,98 @@
+package infuzu
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAuthorizerCachesProfiles(t *testing.T) {
+	service, client := newAccessService(t)
+	service.Set("/access/user-access-profile/user-1/", UserAccessProfile{UserID: "user-1", Permissions: Permissions{"read"}}, 0)
+	service.Set("/access/object-access-profile/user-1/document/", ObjectAccessProfile{
+		UserID: "user-1", ObjectType: "document", Permissions: Permissions{"edit"},
+	}, 0)
+	authorizer := NewAuthorizer(client, time.Minute, 10)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if allowed, err := authorizer.UserHasPermission(ctx, "user-1", "read"); err != nil || !allowed {
+			t.Fatalf("expected read to be allowed, got %v, %v", allowed, err)
+		}
+		if allowed, err := authorizer.ObjectHasPermission(ctx, "user-1", "document", "edit"); err != nil || !allowed {
+			t.Fatalf("expected edit to be allowed, got %v, %v", allowed, err)
+		}
+	}
+	if allowed, _ := authorizer.UserHasPermission(ctx, "user-1", "write"); allowed {
+		t.Fatal("a permission the user lacks was allowed")
+	}
+	if hits := service.Count("GET /access/user-access-profile/user-1/"); hits != 1 {
+		t.Fatalf("user profile was fetched %d times", hits)
+	}
+	if hits := service.Count("GET /access/object-access-profile/user-1/document/"); hits != 1 {
+		t.Fatalf("object profile was fetched %d times", hits)
+	}
+
+	service.Set("/access/user-access-profile/user-1/", UserAccessProfile{UserID: "user-1", Permissions: Permissions{"write"}}, 0)
+	authorizer.Invalidate("user-1")
+	if allowed, _ := authorizer.UserHasPermission(ctx, "user-1", "write"); !allowed {
+		t.Fatal("Invalidate did not refetch the profile")
+	}
+}
+
+func TestAuthorizerCachesDenials(t *testing.T) {
+	service, client := newAccessService(t)
+	service.Set("/access/user-access-profile/forbidden/", nil, http.StatusForbidden)
+	authorizer := NewAuthorizer(client, time.Minute, 10)
+	ctx := context.Background()
+
+	for _, userID := range []string{"missing", "forbidden"} {
+		for i := 0; i < 3; i++ {
+			if allowed, err := authorizer.UserHasPermission(ctx, userID, "read"); err != nil || allowed {
+				t.Fatalf("%s: expected a denial, got %v, %v", userID, allowed, err)
+			}
+		}
+		if hits := service.Count("GET /access/user-access-profile/" + userID + "/"); hits != 1 {
+			t.Fatalf("%s: denial was fetched %d times", userID, hits)
+		}
+	}
+}
+
+func TestAuthorizerReportsRejectedCredentials(t *testing.T) {
+	service, client := newAccessService(t)
+	service.Set("/access/user-access-profile/user-1/", nil, http.StatusUnauthorized)
+	authorizer := NewAuthorizer(client, time.Minute, 10)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := authorizer.UserHasPermission(ctx, "user-1", "read"); !errors.Is(err, ErrUnauthorized) {
+			t.Fatalf("expected ErrUnauthorized, got %v", err)
+		}
+	}
+	if hits := service.Count("GET /access/user-access-profile/user-1/"); hits != 2 {
+		t.Fatalf("rejected credentials were cached, fetched %d times", hits)
+	}
+}
+
+func TestAuthorizerDoesNotCacheOutages(t *testing.T) {
+	service, client := newAccessService(t)
+	service.Set("/access/user-access-profile/user-1/", nil, http.StatusServiceUnavailable)
+	authorizer := NewAuthorizer(client, time.Minute, 10)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := authorizer.UserHasPermission(ctx, "user-1", "read"); err == nil || !strings.Contains(err.Error(), "503") {
+			t.Fatalf("expected the outage to be reported, got %v", err)
+		}
+	}
+	if hits := service.Count("GET /access/user-access-profile/user-1/"); hits != 2 {
+		t.Fatalf("an outage was cached, fetched %d times", hits)
+	}
+	service.Set("/access/user-access-profile/user-1/", UserAccessProfile{UserID: "user-1", IsAdmin: true}, 0)
+	if allowed, err := authorizer.UserHasPermission(ctx, "user-1", "anything"); err != nil || !allowed {
+		t.Fatalf("expected the recovered service to grant access, got %v, %v", allowed, err)
+	}
+}
@@ -0,0 +1,63 @@
+package infuzu
+
+import (
+	"errors"
+	authenticate "github.com/infuzu/infuzu-go-sdk/infuzu/authentication/authenticate"
+	base "github.com/infuzu/infuzu-go-sdk/infuzu/authentication/base"
+	shortcuts "github.com/infuzu/infuzu-go-sdk/infuzu/authentication/shortcuts"
+	"net/http"
+)
+
+var ErrUnverifiedApplication = errors.New("infuzu/access/integration.go request is not signed by a valid application")
+
+// UserIDFromSignedHeader returns the user id a verified application sent in
+// headerName. The header is trusted only when the request carries a 2.0
+// signature that lists it among the signed headers.
+func UserIDFromSignedHeader(application interface{}, header http.Header, headerName string) (string, error) {
+	if !authenticate.ApplicationIsValid(application) {
+		return "", ErrUnverifiedApplication
+	}
+	if !shortcuts.SignatureCoversHeader(header.Get(shortcuts.SignatureHeaderName), headerName) {
+		return "", errors.New("infuzu/access/integration.go user id header is not covered by the signature")
+	}
+	userID := header.Get(headerName)
+	if userID == "" {
+		return "", errors.New("infuzu/access/integration.go user id header is missing")
+	}
+	return userID, nil
+}
+
+// UserIDFromSignedPath returns userID, taken from the request path, only when
+// a verified application sent the request with a 2.0 signature, which covers
+// the path.
+func UserIDFromSignedPath(application interface{}, signature string, userID string) (string, error) {
+	if !authenticate.ApplicationIsValid(application) {
+		return "", ErrUnverifiedApplication
+	}
+	parsed, err := base.ParseSignature(signature)
+	if err != nil || parsed.Version != "2.0" {
+		return "", errors.New("infuzu/access/integration.go request path is not covered by the signature")
+	}
+	if userID == "" {
+		return "", errors.New("infuzu/access/integration.go user id parameter is missing")
+	}
+	return userID, nil
+}
+
+// IsDenial reports whether err means the access service refused or does not
+// know the user, as opposed to the lookup failing.
+func IsDenial(err error) bool {
+	return errors.Is(err, ErrNotFound) || errors.Is(err, ErrForbidden)
+}
+
+// PermissionFailure returns the status and message the integrations respond
+// with after a permission lookup, or 0 when access is granted.
+func PermissionFailure(err error, hasPermission bool) (int, string) {
+	if err != nil && !IsDenial(err) {
+		return http.StatusServiceUnavailable, "Access Denied - Unable to verify permissions"
+	}
+	if err != nil || !hasPermission {
+		return http.StatusForbidden, "Access Denied - User does not have the required permission"
+	}
+	return 0, ""
+}
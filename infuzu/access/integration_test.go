@@ -0,0 +1,72 @@
+package infuzu
+
+import (
+	"errors"
+	base "github.com/infuzu/infuzu-go-sdk/infuzu/authentication/base"
+	auth "github.com/infuzu/infuzu-go-sdk/infuzu/authentication/requests"
+	shortcuts "github.com/infuzu/infuzu-go-sdk/infuzu/authentication/shortcuts"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestUserIDFromSignedRequests(t *testing.T) {
+	keys, err := base.GenerateIKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	application := &auth.Application{}
+	request := httptest.NewRequest("GET", "/users/user-1/", nil)
+	request.Header.Set("X-User-ID", "user-1")
+	requestBound, err := keys.PrivateKey.SignRequest(base.NewSignableRequest(request, nil), []string{"X-User-ID"}, "2.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bodyOnly, err := keys.PrivateKey.SignMessage("", "1.2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	header := http.Header{"X-User-Id": {"user-1"}, shortcuts.SignatureHeaderName: {requestBound}}
+	if userID, err := UserIDFromSignedHeader(application, header, "X-User-ID"); err != nil || userID != "user-1" {
+		t.Fatalf("expected the signed header to be trusted, got %q, %v", userID, err)
+	}
+	if _, err = UserIDFromSignedHeader(nil, header, "X-User-ID"); !errors.Is(err, ErrUnverifiedApplication) {
+		t.Fatalf("expected ErrUnverifiedApplication, got %v", err)
+	}
+	if _, err = UserIDFromSignedHeader(application, header, "X-Other-ID"); err == nil {
+		t.Fatal("a header outside the signature was trusted")
+	}
+
+	if userID, err := UserIDFromSignedPath(application, requestBound, "user-1"); err != nil || userID != "user-1" {
+		t.Fatalf("expected the signed path to be trusted, got %q, %v", userID, err)
+	}
+	if _, err = UserIDFromSignedPath(nil, requestBound, "user-1"); !errors.Is(err, ErrUnverifiedApplication) {
+		t.Fatalf("expected ErrUnverifiedApplication, got %v", err)
+	}
+	if _, err = UserIDFromSignedPath(application, bodyOnly, "user-1"); err == nil {
+		t.Fatal("a path outside the signature was trusted")
+	}
+	if _, err = UserIDFromSignedPath(application, requestBound, ""); err == nil {
+		t.Fatal("an empty user id was accepted")
+	}
+}
+
+func TestPermissionFailure(t *testing.T) {
+	for name, testCase := range map[string]struct {
+		err           error
+		hasPermission bool
+		expected      int
+	}{
+		"granted":              {nil, true, 0},
+		"missing permission":   {nil, false, http.StatusForbidden},
+		"unknown user":         {ErrNotFound, false, http.StatusForbidden},
+		"forbidden":            {ErrForbidden, false, http.StatusForbidden},
+		"rejected credentials": {ErrUnauthorized, false, http.StatusServiceUnavailable},
+		"outage":               {errors.New("unavailable"), false, http.StatusServiceUnavailable},
+	} {
+		if status, _ := PermissionFailure(testCase.err, testCase.hasPermission); status != testCase.expected {
+			t.Fatalf("%s: expected %d, got %d", name, testCase.expected, status)
+		}
+	}
+}
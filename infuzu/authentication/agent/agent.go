@@ -0,0 +1,161 @@
+package infuzu
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	base "github.com/infuzu/infuzu-go-sdk/infuzu/authentication/base"
+	"io"
+	"net"
+	"time"
+)
+
+const DefaultTimeout = 5 * time.Second
+
+const maxMessageSize = 64 * 1024
+
+var (
+	ErrAgentRefused      = errors.New("infuzu/authentication/agent.go signing agent refused the request")
+	ErrMalformedReply    = errors.New("infuzu/authentication/agent.go signing agent sent a malformed reply")
+	ErrUnknownKeyID      = errors.New("infuzu/authentication/agent.go signing agent does not hold the key")
+	ErrAlgorithmMismatch = errors.New("infuzu/authentication/agent.go signing agent key algorithm does not match the request")
+)
+
+// Each connection carries a single newline-terminated JSON request followed by
+// a single newline-terminated JSON reply.
+type signRequest struct {
+	KeyID     string `json:"key_id"`
+	Algorithm string `json:"algorithm"`
+	Digest    []byte `json:"digest"`
+}
+
+type signReply struct {
+	Signature []byte `json:"signature,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+type Signer struct {
+	SocketPath   string
+	KeyPairID    string
+	KeyAlgorithm base.Algorithm
+	Timeout      time.Duration
+}
+
+func NewSigner(socketPath string, keyPairID string, algorithm base.Algorithm) *Signer {
+	return &Signer{
+		SocketPath:   socketPath,
+		KeyPairID:    keyPairID,
+		KeyAlgorithm: algorithm,
+		Timeout:      DefaultTimeout,
+	}
+}
+
+func (s *Signer) KeyID() string {
+	return s.KeyPairID
+}
+
+func (s *Signer) Algorithm() base.Algorithm {
+	if s.KeyAlgorithm == "" {
+		return base.DefaultAlgorithm
+	}
+	return s.KeyAlgorithm
+}
+
+func (s *Signer) Sign(digest []byte) ([]byte, error) {
+	return s.SignContext(context.Background(), digest)
+}
+
+func (s *Signer) SignContext(ctx context.Context, digest []byte) ([]byte, error) {
+	if s.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.Timeout)
+		defer cancel()
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "unix", s.SocketPath)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		if err = conn.SetDeadline(deadline); err != nil {
+			return nil, err
+		}
+	}
+
+	request := signRequest{KeyID: s.KeyPairID, Algorithm: string(s.Algorithm()), Digest: digest}
+	if err = json.NewEncoder(conn).Encode(request); err != nil {
+		return nil, err
+	}
+
+	var reply signReply
+	if err = json.NewDecoder(io.LimitReader(conn, maxMessageSize)).Decode(&reply); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformedReply, err)
+	}
+	if reply.Error != "" {
+		return nil, fmt.Errorf("%w: %s", ErrAgentRefused, reply.Error)
+	}
+	if len(reply.Signature) == 0 {
+		return nil, fmt.Errorf("%w: reply has no signature", ErrMalformedReply)
+	}
+	return reply.Signature, nil
+}
+
+var (
+	_ base.AlgorithmSigner = (*Signer)(nil)
+	_ base.ContextSigner   = (*Signer)(nil)
+)
+
+// Serve answers signing requests on listener using signers, keyed by KeyID,
+// until the listener is closed. It lets a process act as a signing agent.
+func Serve(listener net.Listener, signers ...base.Signer) error {
+	byKeyID := make(map[string]base.Signer, len(signers))
+	for _, signer := range signers {
+		byKeyID[signer.KeyID()] = signer
+	}
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go serveConn(conn, byKeyID)
+	}
+}
+
+func serveConn(conn net.Conn, signers map[string]base.Signer) {
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(DefaultTimeout)); err != nil {
+		return
+	}
+
+	var request signRequest
+	var reply signReply
+	if err := json.NewDecoder(io.LimitReader(conn, maxMessageSize)).Decode(&request); err != nil {
+		reply.Error = fmt.Sprintf("malformed request: %v", err)
+	} else if signature, err := signWith(signers, request); err != nil {
+		reply.Error = err.Error()
+	} else {
+		reply.Signature = signature
+	}
+	_ = json.NewEncoder(conn).Encode(reply)
+}
+
+func signWith(signers map[string]base.Signer, request signRequest) ([]byte, error) {
+	signer, ok := signers[request.KeyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKeyID, request.KeyID)
+	}
+	algorithm, err := base.ParseAlgorithm(request.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	if held := base.SignerAlgorithm(signer); held != algorithm {
+		return nil, fmt.Errorf("%w: holds %s, asked for %s", ErrAlgorithmMismatch, held, algorithm)
+	}
+	return signer.Sign(request.Digest)
+}
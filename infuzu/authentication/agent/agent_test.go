@@ -0,0 +1,61 @@
+package infuzu
+
+import (
+	"errors"
+	base "github.com/infuzu/infuzu-go-sdk/infuzu/authentication/base"
+	signertest "github.com/infuzu/infuzu-go-sdk/infuzu/authentication/signertest"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSignerRoundTrip(t *testing.T) {
+	directory, err := os.MkdirTemp("", "infuzu-agent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(directory)
+	socketPath := filepath.Join(directory, "agent.sock")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	ed25519Signer := signertest.NewSigner("agent-ed25519")
+	p384Keys, err := base.GenerateIKeysWithAlgorithm(base.AlgorithmP384)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go Serve(listener, ed25519Signer, p384Keys.PrivateKey)
+
+	signatures := map[string]struct {
+		signer    *Signer
+		publicKey *base.IPublicKey
+	}{
+		"ed25519": {NewSigner(socketPath, ed25519Signer.KeyID(), base.AlgorithmEd25519), ed25519Signer.PublicKey()},
+		"p384":    {NewSigner(socketPath, p384Keys.ID, base.AlgorithmP384), p384Keys.PublicKey},
+	}
+	for name, testCase := range signatures {
+		t.Run(name, func(t *testing.T) {
+			signature, err := base.SignMessageWithSigner(testCase.signer, "from the agent", "1.2")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if valid, err := testCase.publicKey.VerifySignature("from the agent", signature, 60); !valid || err != nil {
+				t.Fatalf("agent signature did not verify: %v", err)
+			}
+		})
+	}
+
+	if _, err = NewSigner(socketPath, "missing", base.AlgorithmEd25519).Sign([]byte("digest")); !errors.Is(err, ErrAgentRefused) {
+		t.Fatalf("expected unknown key to be refused, got %v", err)
+	}
+	if _, err = NewSigner(socketPath, p384Keys.ID, base.AlgorithmP256).Sign([]byte("digest")); !errors.Is(err, ErrAgentRefused) {
+		t.Fatalf("expected algorithm mismatch to be refused, got %v", err)
+	}
+	if len(ed25519Signer.Digests()) != 1 {
+		t.Fatalf("expected the test signer to record one digest, got %d", len(ed25519Signer.Digests()))
+	}
+}
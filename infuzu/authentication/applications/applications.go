@@ -1,6 +1,7 @@
 package infuzu
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -9,7 +10,12 @@ import (
 	requests "github.com/infuzu/infuzu-go-sdk/infuzu/requests"
 	utils "github.com/infuzu/infuzu-go-sdk/infuzu/utils"
 	"net/http"
-	"strings"
+	"time"
+)
+
+var (
+	ErrUnknownKeyID       = errors.New("infuzu/authentication/applications.go unknown or invalid key id")
+	ErrMalformedKeyResult = errors.New("infuzu/authentication/applications.go key lookup response is malformed")
 )
 
 func FetchMock(keyID string) (*auth.AuthenticationKey, error) {
@@ -17,10 +23,21 @@ func FetchMock(keyID string) (*auth.AuthenticationKey, error) {
 }
 
 func fetchApplicationInformation(keyID string) (*auth.AuthenticationKey, error) {
-	url := constants.IKeysBaseUrl() + strings.ReplaceAll(constants.IKeysKeyPairEndpoint(), "<str:key_id>", keyID)
+	return fetchApplicationInformationContext(context.Background(), keyID)
+}
+
+func fetchApplicationInformationContext(ctx context.Context, keyID string) (*auth.AuthenticationKey, error) {
+	url, err := utils.BuildURL(
+		constants.IKeysBaseUrl(), constants.IKeysKeyPairEndpoint(), map[string]interface{}{"key_id": keyID},
+	)
+	if errors.Is(err, utils.ErrInvalidURLParameter) {
+		return nil, fmt.Errorf("%w: %w", ErrUnknownKeyID, err)
+	}
+	if err != nil {
+		return nil, err
+	}
 	var resp *http.Response
-	var err error
-	resp, err = requests.SignedClient.Request("GET", url, nil, nil)
+	resp, err = requests.SignedClient.RequestContext(ctx, "GET", url, nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -30,27 +47,31 @@ func fetchApplicationInformation(keyID string) (*auth.AuthenticationKey, error)
 		}
 	}()
 
+	switch resp.StatusCode {
+	case http.StatusBadRequest, http.StatusNotFound, http.StatusGone:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKeyID, resp.Status)
+	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, errors.New(
-			fmt.Sprintf(
-				"infuzu/authentication/requests.go failed to fetch application information: %s", resp.Status,
-			),
-		)
+		return nil, requests.NewAPIError("infuzu/authentication/applications.go", "GET", url, resp)
 	}
 
 	var results map[string]interface{}
 	if err = json.NewDecoder(resp.Body).Decode(&results); err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: %w", ErrMalformedKeyResult, err)
 	}
 
 	keyInfo, valid := results["valid"].(map[string]interface{})
 	if !valid {
-		keyInfo, _ = results["invalid"].(map[string]interface{})
+		var invalid bool
+		keyInfo, invalid = results["invalid"].(map[string]interface{})
+		if !invalid {
+			return nil, fmt.Errorf("%w: response did not describe the key", ErrUnknownKeyID)
+		}
 	}
 
 	applicationInfo, ok := keyInfo["application"].(map[string]interface{})
 	if !ok {
-		return nil, errors.New("missing or invalid application info")
+		return nil, fmt.Errorf("%w: missing or invalid application info", ErrMalformedKeyResult)
 	}
 	delete(keyInfo, "application")
 
@@ -81,12 +102,41 @@ func fetchApplicationInformation(keyID string) (*auth.AuthenticationKey, error)
 	return &authenticationKey, nil
 }
 
-var applicationInfoCache = utils.NewCacheSystem(fetchApplicationInformation, 600, 100)
+var applicationInfoCache = utils.NewCacheWithOptions(
+	fetchApplicationInformationContext,
+	utils.CacheOptions[string, *auth.AuthenticationKey]{
+		ExpiryTime:     600 * time.Second,
+		MaxSize:        100,
+		EvictionPolicy: utils.EvictionPolicyLRU,
+		LoadTimeout:    requests.DefaultRequestTimeout,
+		Negative: utils.NegativePolicy{
+			ExpiryTime: 60 * time.Second,
+			MaxSize:    1000,
+			IsNegative: func(err error) bool {
+				return errors.Is(err, ErrUnknownKeyID)
+			},
+		},
+	},
+)
 
 func GetApplicationInformation(keyID string) (*auth.AuthenticationKey, error) {
-	result, err := applicationInfoCache.Get(keyID, false, nil, 0, keyID)
-	if err != nil {
-		return nil, err
-	}
-	return result.(*auth.AuthenticationKey), nil
+	return GetApplicationInformationContext(context.Background(), keyID)
+}
+
+func GetApplicationInformationContext(ctx context.Context, keyID string) (*auth.AuthenticationKey, error) {
+	return applicationInfoCache.Get(ctx, keyID)
+}
+
+func SetApplicationInformationEvictionCallback(
+	callback utils.EvictionCallback[string, *auth.AuthenticationKey],
+) {
+	applicationInfoCache.SetEvictionCallback(callback)
+}
+
+func SetApplicationInformationStalePolicy(policy utils.StalePolicy) {
+	applicationInfoCache.SetStalePolicy(policy)
+}
+
+func GetApplicationInformationCacheStats() map[string]int {
+	return applicationInfoCache.GetStats()
 }
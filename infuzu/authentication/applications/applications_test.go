@@ -0,0 +1,132 @@
+package infuzu
+
+import (
+	"context"
+	"errors"
+	requests "github.com/infuzu/infuzu-go-sdk/infuzu/requests"
+	requeststest "github.com/infuzu/infuzu-go-sdk/infuzu/requests/requeststest"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newKeyServer(t *testing.T, handler http.HandlerFunc) *int32 {
+	t.Helper()
+	var hits int32
+	server := requeststest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("INFUZU_SECRET_KEY", server.PrivateKey)
+	t.Setenv("INFUZU_KEYS_BASE_URL", server.BaseURL())
+	return &hits
+}
+
+func TestKeyLookupRejectsPathTraversal(t *testing.T) {
+	hits := newKeyServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	for _, keyID := range []string{".", "..", "a/b"} {
+		if _, err := fetchApplicationInformationContext(context.Background(), keyID); !errors.Is(err, ErrUnknownKeyID) {
+			t.Fatalf("key id %q: expected ErrUnknownKeyID, got %v", keyID, err)
+		}
+	}
+	if atomic.LoadInt32(hits) != 0 {
+		t.Fatal("an invalid key id reached the key service")
+	}
+}
+
+func TestKeyLookupReportsMisconfiguredEndpoint(t *testing.T) {
+	newKeyServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	t.Setenv("INFUZU_KEYS_KEY_PAIR_ENDPOINT", "api/key/<float:key_id>/")
+	_, err := fetchApplicationInformationContext(context.Background(), "key")
+	if err == nil || errors.Is(err, ErrUnknownKeyID) {
+		t.Fatalf("expected a configuration error distinct from ErrUnknownKeyID, got %v", err)
+	}
+}
+
+func TestUnknownKeysAreNegativelyCached(t *testing.T) {
+	hits := newKeyServer(t, func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+	for i := 0; i < 3; i++ {
+		if _, err := GetApplicationInformationContext(context.Background(), "negative-cache-key"); !errors.Is(err, ErrUnknownKeyID) {
+			t.Fatalf("expected ErrUnknownKeyID, got %v", err)
+		}
+	}
+	if atomic.LoadInt32(hits) != 1 {
+		t.Fatalf("an unknown key was looked up %d times", atomic.LoadInt32(hits))
+	}
+}
+
+func TestUnavailableKeyServiceIsNotNegativelyCached(t *testing.T) {
+	hits := newKeyServer(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	})
+	for i := 0; i < 2; i++ {
+		_, err := GetApplicationInformationContext(context.Background(), "unavailable-key")
+		if apiError, ok := requests.AsAPIError(err); !ok || apiError.StatusCode != http.StatusServiceUnavailable {
+			t.Fatalf("expected a 503 API error, got %v", err)
+		}
+	}
+	if atomic.LoadInt32(hits) != 2 {
+		t.Fatalf("a transient failure was cached, looked up %d times", atomic.LoadInt32(hits))
+	}
+}
+
+func TestKeyLookupHonoursCallerContext(t *testing.T) {
+	release := make(chan struct{})
+	newKeyServer(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+		http.NotFound(w, r)
+	})
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	started := time.Now()
+	if _, err := GetApplicationInformationContext(ctx, "slow-key"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the caller's deadline, got %v", err)
+	}
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Fatalf("the lookup ignored the caller's deadline for %s", elapsed)
+	}
+
+	cancelled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+	if _, err := fetchApplicationInformationContext(cancelled, "slow-key"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the request to carry the context, got %v", err)
+	}
+}
+
+func TestKeyLookupTimesOutOnStalledBody(t *testing.T) {
+	release := make(chan struct{})
+	newKeyServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"valid":`))
+		w.(http.Flusher).Flush()
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	})
+	defer close(release)
+	timeout := requests.SignedClient.RequestTimeout
+	requests.SignedClient.RequestTimeout = 50 * time.Millisecond
+	t.Cleanup(func() { requests.SignedClient.RequestTimeout = timeout })
+
+	started := time.Now()
+	if _, err := fetchApplicationInformationContext(context.Background(), "stalled-key"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the request timeout, got %v", err)
+	}
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Fatalf("the lookup waited %s on a stalled body", elapsed)
+	}
+}
@@ -1,7 +1,7 @@
 package infuzu
 
 import (
-	"errors"
+	"context"
 	"fmt"
 	application "github.com/infuzu/infuzu-go-sdk/infuzu/authentication/applications"
 	base "github.com/infuzu/infuzu-go-sdk/infuzu/authentication/base"
@@ -10,64 +10,127 @@ import (
 	"reflect"
 )
 
+type UnsupportedPublicKeyTypeError struct {
+	Type reflect.Type
+}
+
+func (e *UnsupportedPublicKeyTypeError) Error() string {
+	return fmt.Sprintf(
+		"public key must be of the type AuthenticationKey, IPublicKey IKeys, or string. Instead got %s", e.Type,
+	)
+}
+
+func (e *UnsupportedPublicKeyTypeError) Is(target error) bool {
+	return target == base.ErrInvalidPublicKey
+}
+
 func VerifyDiverseMessageSignature(message string, signature string, publicKey interface{}) (bool, error) {
+	return VerifyDiverseMessageSignatureContext(context.Background(), message, signature, publicKey)
+}
+
+func VerifyDiverseMessageSignatureContext(
+	ctx context.Context, message string, signature string, publicKey interface{},
+) (bool, error) {
+	publicKeyB64, err := diversePublicKeyToBase64(publicKey)
+	if err != nil {
+		return false, err
+	}
+
+	return shortcuts.VerifyMessageSignatureContext(ctx, message, signature, publicKeyB64)
+}
+
+func VerifyDiverseRequestSignature(
+	request *base.SignableRequest, signature string, publicKey interface{},
+) (bool, error) {
+	return VerifyDiverseRequestSignatureContext(context.Background(), request, signature, publicKey)
+}
+
+func VerifyDiverseRequestSignatureContext(
+	ctx context.Context, request *base.SignableRequest, signature string, publicKey interface{},
+) (bool, error) {
+	publicKeyB64, err := diversePublicKeyToBase64(publicKey)
+	if err != nil {
+		return false, err
+	}
+
+	return shortcuts.VerifyRequestSignatureContext(ctx, request, signature, publicKeyB64)
+}
+
+func diversePublicKeyToBase64(publicKey interface{}) (string, error) {
 	var publicKeyB64 string
 	var err error
 
 	switch pk := publicKey.(type) {
 	case *requests.AuthenticationKey:
 		if pk.PublicKeyB64 == nil {
-			return false, errors.New("invalid public key base64")
+			return "", fmt.Errorf("%w: authentication key has no public key", base.ErrInvalidPublicKey)
 		}
 		publicKeyB64 = *pk.PublicKeyB64
 	case *base.IPublicKey:
 		publicKeyB64, err = pk.ToBase64()
 		if err != nil {
-			return false, err
+			return "", err
 		}
 	case *string:
 		publicKeyB64 = *pk
 	case string:
 		publicKeyB64 = pk
 	default:
-		return false, errors.New(fmt.Sprintf("public key must be of the type AuthenticationKey, IPublicKey IKeys, or string. Instead got %s", reflect.TypeOf(publicKey)))
+		return "", &UnsupportedPublicKeyTypeError{Type: reflect.TypeOf(publicKey)}
 	}
 
 	if publicKeyB64 == "" {
-		return false, errors.New("public key base64 is empty")
+		return "", fmt.Errorf("%w: public key base64 is empty", base.ErrInvalidPublicKey)
 	}
 
-	return shortcuts.VerifyMessageSignature(message, signature, publicKeyB64)
+	return publicKeyB64, nil
 }
 
 func ConvertMessageSignatureToApplicationAndVerify(signature string, message string) (*requests.Application, error) {
+	return ConvertMessageSignatureToApplicationAndVerifyContext(context.Background(), signature, message)
+}
+
+func ConvertMessageSignatureToApplicationAndVerifyContext(
+	ctx context.Context, signature string, message string,
+) (*requests.Application, error) {
+	return ConvertRequestSignatureToApplicationAndVerifyContext(
+		ctx, signature, &base.SignableRequest{Body: []byte(message)},
+	)
+}
+
+func ConvertRequestSignatureToApplicationAndVerify(
+	signature string, request *base.SignableRequest,
+) (*requests.Application, error) {
+	return ConvertRequestSignatureToApplicationAndVerifyContext(context.Background(), signature, request)
+}
+
+func ConvertRequestSignatureToApplicationAndVerifyContext(
+	ctx context.Context, signature string, request *base.SignableRequest,
+) (*requests.Application, error) {
 	var pairID string
 	var err error
 	pairID, err = shortcuts.GetKeyPairIDFromSignature(signature)
 	if err != nil {
 		return nil, err
 	}
-	if pairID == "" {
-		return nil, errors.New("invalid signature")
-	}
 
 	var authenticationKey *requests.AuthenticationKey
-	authenticationKey, err = application.GetApplicationInformation(pairID)
+	authenticationKey, err = application.GetApplicationInformationContext(ctx, pairID)
 	if err != nil {
 		return nil, err
 	}
 
 	if authenticationKey.PublicKeyB64 == nil {
-		return nil, errors.New("invalid public key base64")
+		return nil, fmt.Errorf("%w: authentication key has no public key", base.ErrInvalidPublicKey)
 	}
 
 	var sigIsValid bool
-	sigIsValid, err = VerifyDiverseMessageSignature(message, signature, authenticationKey.PublicKeyB64)
+	sigIsValid, err = VerifyDiverseRequestSignatureContext(ctx, request, signature, authenticationKey.PublicKeyB64)
 	if err != nil {
 		return nil, err
 	}
 	if !sigIsValid {
-		return nil, errors.New("invalid signature")
+		return nil, base.ErrInvalidSignature
 	}
 
 	return authenticationKey.Application, nil
@@ -0,0 +1,130 @@
+package infuzu
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+)
+
+type Algorithm string
+
+const (
+	AlgorithmP521    Algorithm = "p521"
+	AlgorithmP384    Algorithm = "p384"
+	AlgorithmP256    Algorithm = "p256"
+	AlgorithmEd25519 Algorithm = "ed25519"
+)
+
+const DefaultAlgorithm = AlgorithmP521
+
+func ParseAlgorithm(name string) (Algorithm, error) {
+	if name == "" {
+		return DefaultAlgorithm, nil
+	}
+	algorithm := Algorithm(name)
+	switch algorithm {
+	case AlgorithmP521, AlgorithmP384, AlgorithmP256, AlgorithmEd25519:
+		return algorithm, nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnsupportedAlgorithm, name)
+}
+
+func (a Algorithm) curve() elliptic.Curve {
+	switch a {
+	case AlgorithmP521:
+		return elliptic.P521()
+	case AlgorithmP384:
+		return elliptic.P384()
+	case AlgorithmP256:
+		return elliptic.P256()
+	}
+	return nil
+}
+
+// Digest returns what a Signer signs for the given content. Ed25519 signs the
+// content itself; P-521 keys keep SHA-256 so that signatures made before other
+// algorithms existed still verify.
+func (a Algorithm) Digest(message []byte) []byte {
+	switch a {
+	case AlgorithmEd25519:
+		return message
+	case AlgorithmP384:
+		hashed := sha512.Sum384(message)
+		return hashed[:]
+	}
+	hashed := sha256.Sum256(message)
+	return hashed[:]
+}
+
+func (a Algorithm) jwkCurve() string {
+	switch a {
+	case AlgorithmP521:
+		return "P-521"
+	case AlgorithmP384:
+		return "P-384"
+	case AlgorithmP256:
+		return "P-256"
+	case AlgorithmEd25519:
+		return "Ed25519"
+	}
+	return ""
+}
+
+func algorithmForJWKCurve(name string) (Algorithm, error) {
+	for _, algorithm := range []Algorithm{AlgorithmP521, AlgorithmP384, AlgorithmP256, AlgorithmEd25519} {
+		if algorithm.jwkCurve() == name {
+			return algorithm, nil
+		}
+	}
+	return "", fmt.Errorf("%w: jwk curve %q", ErrUnsupportedAlgorithm, name)
+}
+
+func algorithmForCurve(curve elliptic.Curve) (Algorithm, error) {
+	if curve != nil {
+		for _, algorithm := range []Algorithm{AlgorithmP521, AlgorithmP384, AlgorithmP256} {
+			if algorithm.curve() == curve {
+				return algorithm, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("%w: unsupported curve", ErrUnsupportedAlgorithm)
+}
+
+func coordinateSize(curve elliptic.Curve) int {
+	return (curve.Params().BitSize + 7) / 8
+}
+
+func publicKeyFromCrypto(key interface{}) (*IPublicKey, error) {
+	var publicKey *IPublicKey
+	switch typedKey := key.(type) {
+	case *ecdsa.PublicKey:
+		publicKey = &IPublicKey{PublicKey: typedKey}
+	case ed25519.PublicKey:
+		publicKey = &IPublicKey{Ed25519PublicKey: typedKey}
+	default:
+		return nil, fmt.Errorf("%w: unsupported key type %T", ErrInvalidPublicKey, key)
+	}
+	if err := publicKey.validate(); err != nil {
+		return nil, err
+	}
+	return publicKey, nil
+}
+
+func privateKeyFromCrypto(key interface{}) (*IPrivateKey, error) {
+	var privateKey *IPrivateKey
+	switch typedKey := key.(type) {
+	case *ecdsa.PrivateKey:
+		privateKey = &IPrivateKey{PrivateKey: typedKey}
+	case ed25519.PrivateKey:
+		privateKey = &IPrivateKey{Ed25519PrivateKey: typedKey}
+	default:
+		return nil, fmt.Errorf("%w: unsupported key type %T", ErrInvalidPrivateKey, key)
+	}
+	if err := privateKey.validate(); err != nil {
+		return nil, err
+	}
+	return privateKey, nil
+}
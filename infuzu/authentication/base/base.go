@@ -2,6 +2,7 @@ package infuzu
 
 import (
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/sha256"
@@ -17,30 +18,47 @@ import (
 	"time"
 )
 
-var curve = elliptic.P521()
-
 type IKey interface {
 	ToBase64() (string, error)
 	FromBase64(encoded string) error
 }
 
 type IPublicKey struct {
-	PublicKey *ecdsa.PublicKey
-	KeyPairID string
+	PublicKey        *ecdsa.PublicKey
+	Ed25519PublicKey ed25519.PublicKey
+	KeyPairID        string
+}
+
+func (pk *IPublicKey) Algorithm() Algorithm {
+	if pk.Ed25519PublicKey != nil {
+		return AlgorithmEd25519
+	}
+	if pk.PublicKey == nil {
+		return ""
+	}
+	algorithm, _ := algorithmForCurve(pk.PublicKey.Curve)
+	return algorithm
 }
 
 func (pk *IPublicKey) ToBase64() (string, error) {
-	publicKeyBytes := elliptic.MarshalCompressed(pk.PublicKey.Curve, pk.PublicKey.X, pk.PublicKey.Y)
-	publicKeyStr := base64.URLEncoding.EncodeToString(publicKeyBytes)
+	if err := pk.validate(); err != nil {
+		return "", err
+	}
+	algorithm := pk.Algorithm()
+	var publicKeyBytes []byte
+	if algorithm == AlgorithmEd25519 {
+		publicKeyBytes = pk.Ed25519PublicKey
+	} else {
+		publicKeyBytes = elliptic.MarshalCompressed(pk.PublicKey.Curve, pk.PublicKey.X, pk.PublicKey.Y)
+	}
 	publicKeyMap := map[string]string{
-		"u": publicKeyStr,
+		"u": base64.URLEncoding.EncodeToString(publicKeyBytes),
 		"i": pk.KeyPairID,
 	}
-	publicKeyJson, err := json.Marshal(publicKeyMap)
-	if err != nil {
-		return "", err
+	if algorithm != DefaultAlgorithm {
+		publicKeyMap["a"] = string(algorithm)
 	}
-	return base64.URLEncoding.EncodeToString(publicKeyJson), nil
+	return encodeKeyMap(publicKeyMap)
 }
 
 func (pk *IPublicKey) FromBase64(encoded string) error {
@@ -48,197 +66,452 @@ func (pk *IPublicKey) FromBase64(encoded string) error {
 	var err error
 	decodedBytes, err = base64.URLEncoding.DecodeString(encoded)
 	if err != nil {
-		return err
+		return fmt.Errorf("%w: %v", ErrInvalidPublicKey, err)
 	}
 	var publicKeyMap map[string]string
 	err = json.Unmarshal(decodedBytes, &publicKeyMap)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPublicKey, err)
+	}
+	var algorithm Algorithm
+	algorithm, err = ParseAlgorithm(publicKeyMap["a"])
 	if err != nil {
 		return err
 	}
-	publicKeyStr := publicKeyMap["u"]
-	pk.KeyPairID = publicKeyMap["i"]
 	var publicKeyBytes []byte
-	publicKeyBytes, err = base64.URLEncoding.DecodeString(publicKeyStr)
+	publicKeyBytes, err = base64.URLEncoding.DecodeString(publicKeyMap["u"])
 	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPublicKey, err)
+	}
+
+	decoded := &IPublicKey{KeyPairID: publicKeyMap["i"]}
+	if algorithm == AlgorithmEd25519 {
+		decoded.Ed25519PublicKey = ed25519.PublicKey(publicKeyBytes)
+	} else {
+		curve := algorithm.curve()
+		x, y := elliptic.UnmarshalCompressed(curve, publicKeyBytes)
+		if x == nil {
+			return fmt.Errorf("%w: point is not on the curve", ErrInvalidPublicKey)
+		}
+		decoded.PublicKey = &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+	}
+	if err = decoded.validate(); err != nil {
 		return err
 	}
-	x, y := elliptic.UnmarshalCompressed(curve, publicKeyBytes)
-	if x == nil {
-		return errors.New("invalid public key")
+	*pk = *decoded
+	return nil
+}
+
+func (pk *IPublicKey) validate() error {
+	if pk.Ed25519PublicKey != nil {
+		if pk.PublicKey != nil {
+			return fmt.Errorf("%w: key holds both ECDSA and Ed25519 material", ErrInvalidPublicKey)
+		}
+		if len(pk.Ed25519PublicKey) != ed25519.PublicKeySize {
+			return fmt.Errorf("%w: Ed25519 key is %d bytes long", ErrInvalidPublicKey, len(pk.Ed25519PublicKey))
+		}
+		return nil
+	}
+	publicKey := pk.PublicKey
+	if publicKey == nil || publicKey.Curve == nil || publicKey.X == nil || publicKey.Y == nil {
+		return fmt.Errorf("%w: key is incomplete", ErrInvalidPublicKey)
 	}
-	pk.PublicKey = &ecdsa.PublicKey{
-		Curve: curve,
-		X:     x,
-		Y:     y,
+	if _, err := algorithmForCurve(publicKey.Curve); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidPublicKey, err)
+	}
+	if !publicKey.Curve.IsOnCurve(publicKey.X, publicKey.Y) {
+		return fmt.Errorf("%w: point is not on the curve", ErrInvalidPublicKey)
 	}
 	return nil
 }
 
+func (pk *IPublicKey) cryptoKey() interface{} {
+	if pk.Ed25519PublicKey != nil {
+		return pk.Ed25519PublicKey
+	}
+	return pk.PublicKey
+}
+
 type IPrivateKey struct {
-	PrivateKey *ecdsa.PrivateKey
-	KeyPairID  string
+	PrivateKey        *ecdsa.PrivateKey
+	Ed25519PrivateKey ed25519.PrivateKey
+	KeyPairID         string
+}
+
+func (sk *IPrivateKey) Algorithm() Algorithm {
+	if sk.Ed25519PrivateKey != nil {
+		return AlgorithmEd25519
+	}
+	if sk.PrivateKey == nil {
+		return ""
+	}
+	algorithm, _ := algorithmForCurve(sk.PrivateKey.Curve)
+	return algorithm
 }
 
+const (
+	privateKeyEnvelopeVersion = "2"
+	privateKeyFormatSEC1      = "sec1"
+	privateKeyFormatPKCS8     = "pkcs8"
+	privateKeyFormatRaw       = "raw"
+)
+
 func (sk *IPrivateKey) ToBase64() (string, error) {
-	var err error
+	if err := sk.validate(); err != nil {
+		return "", err
+	}
+	algorithm := sk.Algorithm()
+	format := privateKeyFormatSEC1
 	var privateKeyBytes []byte
-	privateKeyBytes, err = x509.MarshalECPrivateKey(sk.PrivateKey)
+	var err error
+	if algorithm == AlgorithmEd25519 {
+		format = privateKeyFormatPKCS8
+		privateKeyBytes, err = x509.MarshalPKCS8PrivateKey(sk.Ed25519PrivateKey)
+	} else {
+		privateKeyBytes, err = x509.MarshalECPrivateKey(sk.PrivateKey)
+	}
 	if err != nil {
 		return "", err
 	}
-	privateKeyStr := base64.URLEncoding.EncodeToString(privateKeyBytes)
 	privateKeyMap := map[string]string{
-		"u": privateKeyStr,
+		"v": privateKeyEnvelopeVersion,
+		"f": format,
+		"u": base64.URLEncoding.EncodeToString(privateKeyBytes),
 		"i": sk.KeyPairID,
 	}
-	var privateKeyJson []byte
-	privateKeyJson, err = json.Marshal(privateKeyMap)
-	if err != nil {
+	if algorithm != DefaultAlgorithm {
+		privateKeyMap["a"] = string(algorithm)
+	}
+	return encodeKeyMap(privateKeyMap)
+}
+
+func (sk *IPrivateKey) ToLegacyBase64() (string, error) {
+	if err := sk.validate(); err != nil {
 		return "", err
 	}
-	return base64.URLEncoding.EncodeToString(privateKeyJson), nil
+	if algorithm := sk.Algorithm(); algorithm != AlgorithmP521 {
+		return "", fmt.Errorf("%w: the legacy encoding only supports %s keys", ErrUnsupportedKeyFormat, AlgorithmP521)
+	}
+	scalar := sk.PrivateKey.D.FillBytes(make([]byte, coordinateSize(sk.PrivateKey.Curve)))
+	return encodeKeyMap(map[string]string{
+		"r": base64.URLEncoding.EncodeToString(scalar),
+		"i": sk.KeyPairID,
+	})
 }
 
 func (sk *IPrivateKey) FromBase64(encoded string) error {
 	var err error
 	var decodedBytes []byte
+	if IsEncryptedPrivateKey(encoded) {
+		return ErrEncryptedPrivateKey
+	}
 	decodedBytes, err = base64.URLEncoding.DecodeString(encoded)
 	if err != nil {
-		return err
+		return fmt.Errorf("%w: %v", ErrInvalidPrivateKey, err)
 	}
 
 	var privateKeyMap map[string]string
 	err = json.Unmarshal(decodedBytes, &privateKeyMap)
 	if err != nil {
-		return err
+		return fmt.Errorf("%w: %v", ErrInvalidPrivateKey, err)
 	}
-	privateKeyStr, ok := privateKeyMap["r"]
-	if !ok {
-		return fmt.Errorf("missing key 'r' in private key map")
+
+	format := privateKeyMap["f"]
+	switch privateKeyMap["v"] {
+	case "":
+		if _, hasScalar := privateKeyMap["r"]; hasScalar {
+			format = privateKeyFormatRaw
+		} else {
+			format = privateKeyFormatSEC1
+		}
+	case privateKeyEnvelopeVersion:
+	default:
+		return fmt.Errorf("%w: envelope version %q", ErrUnsupportedKeyFormat, privateKeyMap["v"])
+	}
+	var algorithm Algorithm
+	algorithm, err = ParseAlgorithm(privateKeyMap["a"])
+	if err != nil {
+		return err
 	}
 
-	sk.KeyPairID = privateKeyMap["i"]
-	var privateKeyBytes []byte
-	privateKeyBytes, err = base64.URLEncoding.DecodeString(privateKeyStr)
+	var decoded *IPrivateKey
+	switch format {
+	case privateKeyFormatRaw:
+		decoded, err = decodeRawPrivateKey(privateKeyMap["r"], algorithm)
+	case privateKeyFormatSEC1:
+		decoded, err = decodeDERPrivateKey(privateKeyMap["u"], x509.ParseECPrivateKey)
+	case privateKeyFormatPKCS8:
+		decoded, err = decodeDERPrivateKey(privateKeyMap["u"], x509.ParsePKCS8PrivateKey)
+	default:
+		return fmt.Errorf("%w: %q", ErrUnsupportedKeyFormat, format)
+	}
 	if err != nil {
 		return err
 	}
+	if decoded.Algorithm() != algorithm {
+		return fmt.Errorf(
+			"%w: key material is %s but the envelope declares %s", ErrInvalidPrivateKey, decoded.Algorithm(), algorithm,
+		)
+	}
 
-	privateKeyInt := new(big.Int).SetBytes(privateKeyBytes)
+	decoded.KeyPairID = privateKeyMap["i"]
+	*sk = *decoded
+	return nil
+}
 
+func decodeRawPrivateKey(encoded string, algorithm Algorithm) (*IPrivateKey, error) {
+	if encoded == "" {
+		return nil, fmt.Errorf("%w: missing key 'r' in private key map", ErrInvalidPrivateKey)
+	}
+	curve := algorithm.curve()
+	if curve == nil {
+		return nil, fmt.Errorf("%w: raw scalars are only supported for ECDSA keys", ErrUnsupportedKeyFormat)
+	}
+	privateKeyBytes, err := base64.URLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPrivateKey, err)
+	}
+	if len(privateKeyBytes) > coordinateSize(curve) {
+		return nil, fmt.Errorf("%w: scalar is %d bytes long", ErrInvalidPrivateKey, len(privateKeyBytes))
+	}
 	privateKey := new(ecdsa.PrivateKey)
 	privateKey.PublicKey.Curve = curve
-	privateKey.D = privateKeyInt
-	privateKey.PublicKey.X, privateKey.PublicKey.Y = curve.ScalarBaseMult(privateKey.D.Bytes())
+	privateKey.D = new(big.Int).SetBytes(privateKeyBytes)
+	privateKey.PublicKey.X, privateKey.PublicKey.Y = curve.ScalarBaseMult(privateKeyBytes)
+	return privateKeyFromCrypto(privateKey)
+}
 
-	sk.PrivateKey = privateKey
+func decodeDERPrivateKey[K any](encoded string, parse func(der []byte) (K, error)) (*IPrivateKey, error) {
+	if encoded == "" {
+		return nil, fmt.Errorf("%w: missing key 'u' in private key map", ErrInvalidPrivateKey)
+	}
+	privateKeyBytes, err := base64.URLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPrivateKey, err)
+	}
+	var privateKey K
+	privateKey, err = parse(privateKeyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPrivateKey, err)
+	}
+	return privateKeyFromCrypto(privateKey)
+}
 
+func (sk *IPrivateKey) validate() error {
+	if sk.Ed25519PrivateKey != nil {
+		if sk.PrivateKey != nil {
+			return fmt.Errorf("%w: key holds both ECDSA and Ed25519 material", ErrInvalidPrivateKey)
+		}
+		if len(sk.Ed25519PrivateKey) != ed25519.PrivateKeySize {
+			return fmt.Errorf("%w: Ed25519 key is %d bytes long", ErrInvalidPrivateKey, len(sk.Ed25519PrivateKey))
+		}
+		derived := ed25519.NewKeyFromSeed(sk.Ed25519PrivateKey.Seed())
+		if !derived.Equal(sk.Ed25519PrivateKey) {
+			return fmt.Errorf("%w: public half does not match the seed", ErrInvalidPrivateKey)
+		}
+		return nil
+	}
+	privateKey := sk.PrivateKey
+	if privateKey == nil || privateKey.Curve == nil || privateKey.D == nil || privateKey.X == nil || privateKey.Y == nil {
+		return fmt.Errorf("%w: key is incomplete", ErrInvalidPrivateKey)
+	}
+	if _, err := algorithmForCurve(privateKey.Curve); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidPrivateKey, err)
+	}
+	curve := privateKey.Curve
+	if privateKey.D.Sign() <= 0 || privateKey.D.Cmp(curve.Params().N) >= 0 {
+		return fmt.Errorf("%w: scalar is out of range", ErrInvalidPrivateKey)
+	}
+	if !curve.IsOnCurve(privateKey.X, privateKey.Y) {
+		return fmt.Errorf("%w: public point is not on the curve", ErrInvalidPrivateKey)
+	}
+	x, y := curve.ScalarBaseMult(privateKey.D.Bytes())
+	if x.Cmp(privateKey.X) != 0 || y.Cmp(privateKey.Y) != 0 {
+		return fmt.Errorf("%w: public point does not match the scalar", ErrInvalidPrivateKey)
+	}
 	return nil
 }
 
+func (sk *IPrivateKey) cryptoKey() interface{} {
+	if sk.Ed25519PrivateKey != nil {
+		return sk.Ed25519PrivateKey
+	}
+	return sk.PrivateKey
+}
+
+func encodeKeyMap(keyMap map[string]string) (string, error) {
+	keyJson, err := json.Marshal(keyMap)
+	if err != nil {
+		return "", err
+	}
+	return base64.URLEncoding.EncodeToString(keyJson), nil
+}
+
 func GenerateIPrivateKey() (*IPrivateKey, error) {
-	privateKey, err := ecdsa.GenerateKey(curve, rand.Reader)
+	return GenerateIPrivateKeyWithAlgorithm(DefaultAlgorithm)
+}
+
+func GenerateIPrivateKeyWithAlgorithm(algorithm Algorithm) (*IPrivateKey, error) {
+	privateKey := &IPrivateKey{KeyPairID: utils.CreateUUIDWithoutDash()}
+	var err error
+	if algorithm == AlgorithmEd25519 {
+		_, privateKey.Ed25519PrivateKey, err = ed25519.GenerateKey(rand.Reader)
+	} else if curve := algorithm.curve(); curve != nil {
+		privateKey.PrivateKey, err = ecdsa.GenerateKey(curve, rand.Reader)
+	} else {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedAlgorithm, algorithm)
+	}
 	if err != nil {
 		return nil, err
 	}
-	keyPairID := utils.CreateUUIDWithoutDash()
-	return &IPrivateKey{
-		PrivateKey: privateKey,
-		KeyPairID:  keyPairID,
-	}, nil
+	return privateKey, nil
 }
 
 func (sk *IPrivateKey) SignMessage(message string, version string) (string, error) {
-	if version == "1.0" {
-		timestamp := time.Now().Unix()
-		messageWithMetadata := map[string]interface{}{
-			"id":        sk.KeyPairID,
-			"message":   message,
-			"timestamp": timestamp,
-		}
-		var messageJson []byte
-		var err error
-		messageJson, err = canonicaljson.Marshal(messageWithMetadata)
-		if err != nil {
-			return "", err
-		}
-		hashed := sha256.Sum256(messageJson)
-
-		var r, s *big.Int
-		r, s, err = ecdsa.Sign(rand.Reader, sk.PrivateKey, hashed[:])
-		if err != nil {
-			return "", err
-		}
+	return SignMessageWithSigner(sk, message, version)
+}
 
-		var derSig []byte
-		derSig, err = asn1.Marshal(EcdsaSignature{
-			R: r,
-			S: s,
-		})
-		if err != nil {
-			return "", err
+func SignMessageWithSigner(signer Signer, message string, version string) (string, error) {
+	if signer == nil {
+		return "", ErrNilSigner
+	}
+	algorithm := SignerAlgorithm(signer)
+	keyPairID := signer.KeyID()
+	timestamp := time.Now().Unix()
+	var messageWithMetadata, fullSignatureMap map[string]interface{}
+	var signatureField string
+	switch version {
+	case "1.0":
+		if algorithm != AlgorithmP521 {
+			return "", fmt.Errorf("%w: version 1.0 only supports %s keys", ErrUnsupportedVersion, AlgorithmP521)
 		}
-
-		baseSignatureStr := base64.URLEncoding.EncodeToString(derSig)
-		fullSignatureMap := map[string]interface{}{
-			"signature": baseSignatureStr,
+		messageWithMetadata = map[string]interface{}{
+			"id":        keyPairID,
+			"message":   message,
 			"timestamp": timestamp,
-			"id":        sk.KeyPairID,
 		}
-		var fullSignatureJson []byte
-		fullSignatureJson, err = json.Marshal(fullSignatureMap)
-		if err != nil {
-			return "", err
+		fullSignatureMap = map[string]interface{}{
+			"timestamp": timestamp,
+			"id":        keyPairID,
 		}
-
-		return base64.URLEncoding.EncodeToString(fullSignatureJson), nil
-	} else if version == "1.2" {
-		timestamp := time.Now().Unix()
-		messageWithMetadata := map[string]interface{}{
-			"i": sk.KeyPairID,
+		signatureField = "signature"
+	case "1.2":
+		messageWithMetadata = map[string]interface{}{
+			"i": keyPairID,
 			"m": message,
 			"t": timestamp,
 		}
-		var messageJson []byte
-		var err error
-		messageJson, err = canonicaljson.Marshal(messageWithMetadata)
-		if err != nil {
-			return "", err
+		fullSignatureMap = map[string]interface{}{
+			"t": timestamp,
+			"i": keyPairID,
+			"v": "1.2",
 		}
-		hashed := sha256.Sum256(messageJson)
+		signatureField = "s"
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedVersion, version)
+	}
 
-		var r, s *big.Int
-		r, s, err = ecdsa.Sign(rand.Reader, sk.PrivateKey, hashed[:])
-		if err != nil {
-			return "", err
-		}
+	baseSignatureStr, err := signCanonical(signer, algorithm, messageWithMetadata)
+	if err != nil {
+		return "", err
+	}
+	fullSignatureMap[signatureField] = baseSignatureStr
+	return encodeSignatureMap(fullSignatureMap, algorithm)
+}
 
-		var derSig []byte
-		derSig, err = asn1.Marshal(EcdsaSignature{
-			R: r,
-			S: s,
-		})
-		if err != nil {
-			return "", err
-		}
+func (sk *IPrivateKey) SignRequest(request *SignableRequest, signedHeaders []string, version string) (string, error) {
+	return SignRequestWithSigner(sk, request, signedHeaders, version)
+}
 
-		baseSignatureStr := base64.URLEncoding.EncodeToString(derSig)
-		fullSignatureMap := map[string]interface{}{
-			"s": baseSignatureStr,
-			"t": timestamp,
-			"i": sk.KeyPairID,
-			"v": "1.2",
-		}
-		var fullSignatureJson []byte
-		fullSignatureJson, err = json.Marshal(fullSignatureMap)
-		if err != nil {
-			return "", err
+func SignRequestWithSigner(
+	signer Signer, request *SignableRequest, signedHeaders []string, version string,
+) (string, error) {
+	if version != "2.0" {
+		if request == nil {
+			return SignMessageWithSigner(signer, "", version)
 		}
+		return SignMessageWithSigner(signer, string(request.Body), version)
+	}
+	if signer == nil {
+		return "", ErrNilSigner
+	}
+	algorithm := SignerAlgorithm(signer)
+	keyPairID := signer.KeyID()
+	if request == nil {
+		return "", errors.New("request to sign cannot be nil")
+	}
+	timestamp := time.Now().Unix()
+	nonce := utils.CreateUUIDWithoutDash()
+	signedHeaders = normalizeSignedHeaders(signedHeaders)
+	canonicalRequest, err := request.canonicalForm(keyPairID, timestamp, nonce, signedHeaders)
+	if err != nil {
+		return "", err
+	}
+	var baseSignatureStr string
+	baseSignatureStr, err = signCanonical(signer, algorithm, canonicalRequest)
+	if err != nil {
+		return "", err
+	}
+	fullSignatureMap := map[string]interface{}{
+		"s": baseSignatureStr,
+		"t": timestamp,
+		"i": keyPairID,
+		"h": signedHeaders,
+		"n": nonce,
+		"v": "2.0",
+	}
+	return encodeSignatureMap(fullSignatureMap, algorithm)
+}
 
-		return base64.URLEncoding.EncodeToString(fullSignatureJson), nil
-	} else {
-		return "", fmt.Errorf("unsupported version: %s", version)
+func encodeSignatureMap(fullSignatureMap map[string]interface{}, algorithm Algorithm) (string, error) {
+	if algorithm != DefaultAlgorithm {
+		fullSignatureMap["a"] = string(algorithm)
+	}
+	fullSignatureJson, err := json.Marshal(fullSignatureMap)
+	if err != nil {
+		return "", err
+	}
+	return base64.URLEncoding.EncodeToString(fullSignatureJson), nil
+}
+
+func signCanonical(signer Signer, algorithm Algorithm, canonical map[string]interface{}) (string, error) {
+	messageJson, err := canonicaljson.Marshal(canonical)
+	if err != nil {
+		return "", err
+	}
+	var signature []byte
+	signature, err = signer.Sign(algorithm.Digest(messageJson))
+	if err != nil {
+		return "", err
+	}
+	return base64.URLEncoding.EncodeToString(signature), nil
+}
+
+func (sk *IPrivateKey) KeyID() string {
+	return sk.KeyPairID
+}
+
+// Sign trusts that the key was validated when it was generated or decoded, and
+// only refuses keys that hold no key material at all.
+func (sk *IPrivateKey) Sign(digest []byte) ([]byte, error) {
+	if sk == nil {
+		return nil, ErrNilSigner
+	}
+	if sk.Ed25519PrivateKey == nil && (sk.PrivateKey == nil || sk.PrivateKey.D == nil) {
+		return nil, fmt.Errorf("%w: key is incomplete", ErrInvalidPrivateKey)
+	}
+	if sk.Ed25519PrivateKey != nil {
+		return ed25519.Sign(sk.Ed25519PrivateKey, digest), nil
+	}
+
+	r, s, err := ecdsa.Sign(rand.Reader, sk.PrivateKey, digest)
+	if err != nil {
+		return nil, err
 	}
+	return asn1.Marshal(EcdsaSignature{
+		R: r,
+		S: s,
+	})
 }
 
 type EcdsaSignature struct {
@@ -246,108 +519,208 @@ type EcdsaSignature struct {
 }
 
 func (sk *IPrivateKey) PublicKey() *IPublicKey {
+	if sk.Ed25519PrivateKey != nil {
+		return &IPublicKey{
+			Ed25519PublicKey: sk.Ed25519PrivateKey.Public().(ed25519.PublicKey),
+			KeyPairID:        sk.KeyPairID,
+		}
+	}
 	return &IPublicKey{
 		PublicKey: &sk.PrivateKey.PublicKey,
 		KeyPairID: sk.KeyPairID,
 	}
 }
 
+func ParseSignature(signature string) (*ParsedSignature, error) {
+	return utils.ParseSignature(signature)
+}
+
 func (pk *IPublicKey) VerifySignature(message string, signature string, allowedTimeDifference int) (bool, error) {
-	var decodedSignature []byte
-	var err error
-	decodedSignature, err = base64.URLEncoding.DecodeString(signature)
+	parsed, err := ParseSignature(signature)
 	if err != nil {
 		return false, err
 	}
-	var signatureMap map[string]interface{}
-	err = json.Unmarshal(decodedSignature, &signatureMap)
+	return pk.verifyParsedSignature(message, parsed, allowedTimeDifference)
+}
+
+func (pk *IPublicKey) verifyParsedSignature(
+	message string, parsed *ParsedSignature, allowedTimeDifference int,
+) (bool, error) {
+	var messageWithMetadata map[string]interface{}
+	marshal := canonicaljson.Marshal
+	switch parsed.Version {
+	case "1.0":
+		messageWithMetadata = map[string]interface{}{
+			"message":   message,
+			"timestamp": parsed.Timestamp,
+			"id":        parsed.KeyPairID,
+		}
+		marshal = json.Marshal
+	case "1.2":
+		messageWithMetadata = map[string]interface{}{
+			"m": message,
+			"t": parsed.Timestamp,
+			"i": parsed.KeyPairID,
+		}
+	case "2.0":
+		return false, fmt.Errorf(
+			"%w: version 2.0 binds the HTTP request and must be verified with VerifyRequestSignature",
+			ErrUnsupportedVersion,
+		)
+	default:
+		return false, fmt.Errorf("%w: %s", ErrUnsupportedVersion, parsed.Version)
+	}
+
+	if err := pk.checkSignatureMetadata(parsed, allowedTimeDifference); err != nil {
+		return false, err
+	}
+	messageJson, err := marshal(messageWithMetadata)
 	if err != nil {
 		return false, err
 	}
+	return pk.verify(messageJson, parsed.Signature)
+}
 
-	version, ok := signatureMap["v"].(string)
-	if !ok {
-		version = "1.0"
+func (pk *IPublicKey) VerifyRequestSignature(
+	request *SignableRequest, signature string, allowedTimeDifference int,
+) (bool, error) {
+	if request == nil {
+		return false, errors.New("request to verify cannot be nil")
+	}
+	parsed, err := ParseSignature(signature)
+	if err != nil {
+		return false, err
+	}
+	if parsed.Version != "2.0" {
+		return pk.verifyParsedSignature(string(request.Body), parsed, allowedTimeDifference)
 	}
 
-	switch version {
-	case "1.0":
-		sigTimestamp := int64(signatureMap["timestamp"].(float64))
-		var sigSignature []byte
-		sigSignature, err = base64.URLEncoding.DecodeString(signatureMap["signature"].(string))
-		if err != nil {
-			return false, err
-		}
-		sigID := signatureMap["id"].(string)
-
-		if sigID != pk.KeyPairID {
-			return false, nil
-		}
+	if err = pk.checkSignatureMetadata(parsed, allowedTimeDifference); err != nil {
+		return false, err
+	}
 
-		if time.Now().Unix()-sigTimestamp > int64(allowedTimeDifference) {
-			return false, nil
-		}
+	var canonicalRequest map[string]interface{}
+	canonicalRequest, err = request.canonicalForm(
+		parsed.KeyPairID, parsed.Timestamp, parsed.Nonce, normalizeSignedHeaders(parsed.SignedHeaders),
+	)
+	if err != nil {
+		return false, err
+	}
+	var messageJson []byte
+	messageJson, err = canonicaljson.Marshal(canonicalRequest)
+	if err != nil {
+		return false, err
+	}
+	return pk.verify(messageJson, parsed.Signature)
+}
 
-		messageWithMetadata := map[string]interface{}{
-			"message":   message,
-			"timestamp": sigTimestamp,
-			"id":        sigID,
-		}
-		var messageJson []byte
-		messageJson, err = json.Marshal(messageWithMetadata)
-		if err != nil {
-			return false, err
-		}
-		hashed := sha256.Sum256(messageJson)
+func (pk *IPublicKey) checkSignatureMetadata(parsed *ParsedSignature, allowedTimeDifference int) error {
+	if parsed.KeyPairID != pk.KeyPairID {
+		return fmt.Errorf("%w: got %q, expected %q", ErrKeyIDMismatch, parsed.KeyPairID, pk.KeyPairID)
+	}
+	algorithm, err := ParseAlgorithm(parsed.Algorithm)
+	if err != nil {
+		return err
+	}
+	if algorithm != pk.Algorithm() {
+		return fmt.Errorf("%w: signed with %s, key is %s", ErrAlgorithmMismatch, algorithm, pk.Algorithm())
+	}
+	if age := time.Now().Unix() - parsed.Timestamp; age > int64(allowedTimeDifference) {
+		return fmt.Errorf("%w: signed %d seconds ago", ErrSignatureExpired, age)
+	}
+	return nil
+}
 
-		var esig EcdsaSignature
-		_, err = asn1.Unmarshal(sigSignature, &esig)
-		if err != nil {
-			return false, err
+func (pk *IPublicKey) verify(message []byte, signature []byte) (bool, error) {
+	if err := pk.validate(); err != nil {
+		return false, err
+	}
+	algorithm := pk.Algorithm()
+	if algorithm == AlgorithmEd25519 {
+		if len(signature) != ed25519.SignatureSize {
+			return false, fmt.Errorf("%w: Ed25519 signature is %d bytes long", ErrMalformedSignature, len(signature))
 		}
-
-		valid := ecdsa.Verify(pk.PublicKey, hashed[:], esig.R, esig.S)
-		return valid, nil
-	case "1.2":
-		sigTimestamp := int64(signatureMap["t"].(float64))
-		var sigSignature []byte
-		sigSignature, err = base64.URLEncoding.DecodeString(signatureMap["s"].(string))
-		if err != nil {
-			return false, err
+		if !ed25519.Verify(pk.Ed25519PublicKey, message, signature) {
+			return false, ErrInvalidSignature
 		}
-		sigID := signatureMap["i"].(string)
+		return true, nil
+	}
 
-		if sigID != pk.KeyPairID {
-			return false, nil
-		}
+	esig, err := decodeEcdsaSignature(signature)
+	if err != nil {
+		return false, err
+	}
+	if !ecdsa.Verify(pk.PublicKey, algorithm.Digest(message), esig.R, esig.S) {
+		return false, ErrInvalidSignature
+	}
+	return true, nil
+}
 
-		if time.Now().Unix()-sigTimestamp > int64(allowedTimeDifference) {
-			return false, nil
-		}
+func decodeEcdsaSignature(signature []byte) (*EcdsaSignature, error) {
+	var esig EcdsaSignature
+	rest, err := asn1.Unmarshal(signature, &esig)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformedSignature, err)
+	}
+	if len(rest) != 0 {
+		return nil, fmt.Errorf("%w: %d trailing bytes after the signature", ErrMalformedSignature, len(rest))
+	}
+	if esig.R == nil || esig.S == nil || esig.R.Sign() <= 0 || esig.S.Sign() <= 0 {
+		return nil, fmt.Errorf("%w: signature values must be positive", ErrMalformedSignature)
+	}
+	return &esig, nil
+}
 
-		messageWithMetadata := map[string]interface{}{
-			"m": message,
-			"t": sigTimestamp,
-			"i": sigID,
-		}
-		var messageJson []byte
-		messageJson, err = canonicaljson.Marshal(messageWithMetadata)
-		if err != nil {
-			return false, err
-		}
-		hashed := sha256.Sum256(messageJson)
+func SignatureReplayIdentity(signature string) (string, int64, error) {
+	parsed, err := ParseSignature(signature)
+	if err != nil {
+		return "", 0, err
+	}
+	if parsed.Nonce != "" {
+		return parsed.KeyPairID + ":n:" + parsed.Nonce, parsed.Timestamp, nil
+	}
+	var normalized []byte
+	normalized, err = normalizeSignature(parsed)
+	if err != nil {
+		return "", 0, err
+	}
+	signatureDigest := sha256.Sum256(normalized)
+	return parsed.KeyPairID + ":s:" + base64.URLEncoding.EncodeToString(signatureDigest[:]), parsed.Timestamp, nil
+}
 
-		var esig EcdsaSignature
-		_, err = asn1.Unmarshal(sigSignature, &esig)
-		if err != nil {
-			return false, err
+// ECDSA signatures stay valid when s is replaced by N-s, so replay is keyed on
+// the low-s form rather than on the bytes the caller sent.
+func normalizeSignature(parsed *ParsedSignature) ([]byte, error) {
+	algorithm, err := ParseAlgorithm(parsed.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	if algorithm == AlgorithmEd25519 {
+		if len(parsed.Signature) != ed25519.SignatureSize {
+			return nil, fmt.Errorf(
+				"%w: Ed25519 signature is %d bytes long", ErrMalformedSignature, len(parsed.Signature),
+			)
 		}
+		return parsed.Signature, nil
+	}
 
-		valid := ecdsa.Verify(pk.PublicKey, hashed[:], esig.R, esig.S)
-		return valid, nil
-	default:
-		return false, fmt.Errorf("unsupported version: %s", version)
+	esig, err := decodeEcdsaSignature(parsed.Signature)
+	if err != nil {
+		return nil, err
+	}
+	order := algorithm.curve().Params().N
+	if esig.R.Cmp(order) >= 0 || esig.S.Cmp(order) >= 0 {
+		return nil, fmt.Errorf("%w: signature values exceed the curve order", ErrMalformedSignature)
 	}
+	s := esig.S
+	if complement := new(big.Int).Sub(order, s); complement.Cmp(s) < 0 {
+		s = complement
+	}
+	size := coordinateSize(algorithm.curve())
+	normalized := make([]byte, 2*size)
+	esig.R.FillBytes(normalized[:size])
+	s.FillBytes(normalized[size:])
+	return normalized, nil
 }
 
 type IKeys struct {
@@ -357,7 +730,11 @@ type IKeys struct {
 }
 
 func GenerateIKeys() (*IKeys, error) {
-	privateKey, err := GenerateIPrivateKey()
+	return GenerateIKeysWithAlgorithm(DefaultAlgorithm)
+}
+
+func GenerateIKeysWithAlgorithm(algorithm Algorithm) (*IKeys, error) {
+	privateKey, err := GenerateIPrivateKeyWithAlgorithm(algorithm)
 	if err != nil {
 		return nil, err
 	}
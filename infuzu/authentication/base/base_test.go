@@ -0,0 +1,635 @@
+package infuzu
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func mustGenerateIKeys(t *testing.T) *IKeys {
+	t.Helper()
+	keys, err := GenerateIKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keys
+}
+
+func assertSameKey(t *testing.T, expected *IPrivateKey, actual *IPrivateKey) {
+	t.Helper()
+	if actual.KeyPairID != expected.KeyPairID {
+		t.Fatalf("key pair id %q, expected %q", actual.KeyPairID, expected.KeyPairID)
+	}
+	if actual.Algorithm() != expected.Algorithm() || !actual.cryptoKey().(interface{ Equal(crypto.PrivateKey) bool }).Equal(expected.cryptoKey()) {
+		t.Fatal("decoded private key does not match the original")
+	}
+	signature, err := actual.SignMessage("round trip", "1.2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if valid, err := expected.PublicKey().VerifySignature("round trip", signature, 60); !valid || err != nil {
+		t.Fatalf("signature from decoded key did not verify: %v", err)
+	}
+}
+
+func TestPrivateKeyRoundTrip(t *testing.T) {
+	keys := mustGenerateIKeys(t)
+	legacyDER, err := x509.MarshalECPrivateKey(keys.PrivateKey.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacyDEREncoded, err := encodeKeyMap(map[string]string{
+		"u": base64.URLEncoding.EncodeToString(legacyDER),
+		"i": keys.ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoders := map[string]func() (string, error){
+		"envelope":   keys.PrivateKey.ToBase64,
+		"legacy raw": keys.PrivateKey.ToLegacyBase64,
+		"legacy der": func() (string, error) { return legacyDEREncoded, nil },
+	}
+	for name, encode := range encoders {
+		t.Run(name, func(t *testing.T) {
+			encoded, err := encode()
+			if err != nil {
+				t.Fatal(err)
+			}
+			decoded := &IPrivateKey{}
+			if err = decoded.FromBase64(encoded); err != nil {
+				t.Fatal(err)
+			}
+			assertSameKey(t, keys.PrivateKey, decoded)
+
+			reencoded, err := decoded.ToBase64()
+			if err != nil {
+				t.Fatal(err)
+			}
+			again := &IPrivateKey{}
+			if err = again.FromBase64(reencoded); err != nil {
+				t.Fatal(err)
+			}
+			assertSameKey(t, keys.PrivateKey, again)
+		})
+	}
+}
+
+func TestPublicKeyRoundTrip(t *testing.T) {
+	keys := mustGenerateIKeys(t)
+	encoded, err := keys.PublicKey.ToBase64()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := &IPublicKey{}
+	if err = decoded.FromBase64(encoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.KeyPairID != keys.ID || !decoded.PublicKey.Equal(keys.PublicKey.PublicKey) {
+		t.Fatal("decoded public key does not match the original")
+	}
+}
+
+func TestPrivateKeyRejectsInvalidEncodings(t *testing.T) {
+	otherCurveKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherCurveDER, err := x509.MarshalECPrivateKey(otherCurveKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	order := elliptic.P521().Params().N.FillBytes(make([]byte, 66))
+	tooLong := make([]byte, 67)
+	tooLong[66] = 1
+
+	cases := map[string]struct {
+		keyMap   map[string]string
+		expected error
+	}{
+		"zero scalar":       {map[string]string{"r": base64.URLEncoding.EncodeToString([]byte{0})}, ErrInvalidPrivateKey},
+		"scalar equal to n": {map[string]string{"r": base64.URLEncoding.EncodeToString(order)}, ErrInvalidPrivateKey},
+		"oversized scalar":  {map[string]string{"r": base64.URLEncoding.EncodeToString(tooLong)}, ErrInvalidPrivateKey},
+		"missing key":       {map[string]string{"i": "id"}, ErrInvalidPrivateKey},
+		"garbage der":       {map[string]string{"u": base64.URLEncoding.EncodeToString([]byte("nope"))}, ErrInvalidPrivateKey},
+		"wrong curve":       {map[string]string{"u": base64.URLEncoding.EncodeToString(otherCurveDER)}, ErrInvalidPrivateKey},
+		"future version":    {map[string]string{"v": "99", "f": "sec1"}, ErrUnsupportedKeyFormat},
+		"unknown format":    {map[string]string{"v": privateKeyEnvelopeVersion, "f": "tpm"}, ErrUnsupportedKeyFormat},
+	}
+	for name, testCase := range cases {
+		t.Run(name, func(t *testing.T) {
+			encoded, err := encodeKeyMap(testCase.keyMap)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err = (&IPrivateKey{}).FromBase64(encoded); !errors.Is(err, testCase.expected) {
+				t.Fatalf("expected %v, got %v", testCase.expected, err)
+			}
+		})
+	}
+}
+
+func TestPEMRoundTrip(t *testing.T) {
+	keys := mustGenerateIKeys(t)
+	encoders := map[string]func() ([]byte, error){
+		"pkcs8": keys.PrivateKey.ToPEM,
+		"sec1":  keys.PrivateKey.ToSEC1PEM,
+	}
+	for name, encode := range encoders {
+		t.Run(name, func(t *testing.T) {
+			encoded, err := encode()
+			if err != nil {
+				t.Fatal(err)
+			}
+			decoded := &IPrivateKey{}
+			if err = decoded.FromPEM(encoded, keys.ID); err != nil {
+				t.Fatal(err)
+			}
+			assertSameKey(t, keys.PrivateKey, decoded)
+		})
+	}
+
+	encoded, err := keys.PublicKey.ToPEM()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := &IPublicKey{}
+	if err = decoded.FromPEM(encoded, keys.ID); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.KeyPairID != keys.ID || !decoded.PublicKey.Equal(keys.PublicKey.PublicKey) {
+		t.Fatal("decoded public key does not match the original")
+	}
+	if err = (&IPrivateKey{}).FromPEM(encoded, keys.ID); !errors.Is(err, ErrInvalidPrivateKey) {
+		t.Fatalf("expected public PEM to be rejected as a private key, got %v", err)
+	}
+}
+
+func TestJWKRoundTrip(t *testing.T) {
+	keys := mustGenerateIKeys(t)
+	privateJWK, err := keys.PrivateKey.ToJWK()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if privateJWK.KeyID != keys.ID || privateJWK.Curve != "P-521" || privateJWK.D == "" {
+		t.Fatalf("unexpected private jwk %+v", privateJWK)
+	}
+	decodedPrivate := &IPrivateKey{}
+	if err = decodedPrivate.FromJWK(privateJWK); err != nil {
+		t.Fatal(err)
+	}
+	assertSameKey(t, keys.PrivateKey, decodedPrivate)
+
+	jwks, err := NewJWKS(keys.PublicKey, mustGenerateIKeys(t).PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, jwk := range jwks.Keys {
+		if jwk.D != "" {
+			t.Fatal("public key set leaked a private scalar")
+		}
+	}
+	publicKey, err := jwks.PublicKey(keys.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !publicKey.PublicKey.Equal(keys.PublicKey.PublicKey) {
+		t.Fatal("key set returned the wrong public key")
+	}
+	if _, err = jwks.PublicKey("missing"); !errors.Is(err, ErrInvalidPublicKey) {
+		t.Fatalf("expected missing key error, got %v", err)
+	}
+
+	tampered := *privateJWK
+	tampered.Y = tampered.X
+	if err = (&IPublicKey{}).FromJWK(&tampered); !errors.Is(err, ErrInvalidPublicKey) {
+		t.Fatalf("expected off-curve point to be rejected, got %v", err)
+	}
+	tampered = *privateJWK
+	tampered.D = base64.RawURLEncoding.EncodeToString(make([]byte, 66))
+	if err = (&IPrivateKey{}).FromJWK(&tampered); !errors.Is(err, ErrInvalidPrivateKey) {
+		t.Fatalf("expected zero scalar to be rejected, got %v", err)
+	}
+}
+
+var fastScryptParameters = ScryptParameters{N: 1 << 10, R: 8, P: 1}
+
+func TestEncryptedPrivateKeyRoundTrip(t *testing.T) {
+	keys := mustGenerateIKeys(t)
+	passphrase := []byte("correct horse battery staple")
+	encrypted, err := keys.PrivateKey.ToEncryptedBase64(passphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsEncryptedPrivateKey(encrypted) {
+		t.Fatal("encrypted key was not recognised")
+	}
+	plaintext, err := keys.PrivateKey.ToBase64()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if IsEncryptedPrivateKey(plaintext) {
+		t.Fatal("plaintext key was recognised as encrypted")
+	}
+
+	decoded := &IPrivateKey{}
+	if err = decoded.FromEncryptedBase64(encrypted, passphrase); err != nil {
+		t.Fatal(err)
+	}
+	assertSameKey(t, keys.PrivateKey, decoded)
+
+	if err = (&IPrivateKey{}).FromBase64(encrypted); !errors.Is(err, ErrEncryptedPrivateKey) {
+		t.Fatalf("expected encrypted key error, got %v", err)
+	}
+	if err = (&IPrivateKey{}).FromEncryptedBase64(encrypted, []byte("wrong")); !errors.Is(err, ErrIncorrectPassphrase) {
+		t.Fatalf("expected incorrect passphrase error, got %v", err)
+	}
+	if err = (&IPrivateKey{}).FromEncryptedBase64(plaintext, passphrase); !errors.Is(err, ErrUnsupportedKeyFormat) {
+		t.Fatalf("expected plaintext key to be rejected, got %v", err)
+	}
+}
+
+func TestEncryptedPrivateKeyDetectsTampering(t *testing.T) {
+	keys := mustGenerateIKeys(t)
+	passphrase := []byte("passphrase")
+	encrypted, err := keys.PrivateKey.ToEncryptedBase64WithParameters(passphrase, fastScryptParameters)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decodedBytes, err := base64.URLEncoding.DecodeString(encrypted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var envelope encryptedPrivateKey
+	if err = json.Unmarshal(decodedBytes, &envelope); err != nil {
+		t.Fatal(err)
+	}
+
+	envelope.KeyPairID = "someone-else"
+	tampered, err := json.Marshal(envelope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = (&IPrivateKey{}).FromEncryptedBase64(base64.URLEncoding.EncodeToString(tampered), passphrase)
+	if !errors.Is(err, ErrIncorrectPassphrase) {
+		t.Fatalf("expected tampered header to fail authentication, got %v", err)
+	}
+
+	envelope.Derivation.N = 1 << 30
+	tampered, err = json.Marshal(envelope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = (&IPrivateKey{}).FromEncryptedBase64(base64.URLEncoding.EncodeToString(tampered), passphrase)
+	if !errors.Is(err, ErrInvalidPrivateKey) {
+		t.Fatalf("expected excessive scrypt cost to be rejected, got %v", err)
+	}
+}
+
+func TestScryptParametersBoundMemory(t *testing.T) {
+	cases := map[string]struct {
+		parameters ScryptParameters
+		valid      bool
+	}{
+		"default":              {DefaultScryptParameters, true},
+		"largest cost":         {ScryptParameters{N: 1 << 21, R: 1, P: 1}, true},
+		"largest block size":   {ScryptParameters{N: 1 << 10, R: 2048, P: 1}, true},
+		"large cost and size":  {ScryptParameters{N: 1 << 20, R: 8, P: 1}, false},
+		"oversized block size": {ScryptParameters{N: 1 << 10, R: 4096, P: 1}, false},
+		"cost not power of 2":  {ScryptParameters{N: 3 << 10, R: 8, P: 1}, false},
+		"zero parallelism":     {ScryptParameters{N: 1 << 10, R: 8, P: 0}, false},
+	}
+	for name, testCase := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := testCase.parameters.validate()
+			if testCase.valid && err != nil {
+				t.Fatalf("expected %+v to be accepted, got %v", testCase.parameters, err)
+			}
+			if !testCase.valid && !errors.Is(err, ErrInvalidPrivateKey) {
+				t.Fatalf("expected %+v to be rejected, got %v", testCase.parameters, err)
+			}
+		})
+	}
+}
+
+func TestAlgorithmRoundTrips(t *testing.T) {
+	request := &SignableRequest{Method: "POST", Path: "/v1/items", Host: "api.infuzu.com", Body: []byte(`{"a":1}`)}
+	for _, algorithm := range []Algorithm{AlgorithmP521, AlgorithmP384, AlgorithmP256, AlgorithmEd25519} {
+		t.Run(string(algorithm), func(t *testing.T) {
+			keys, err := GenerateIKeysWithAlgorithm(algorithm)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if keys.PrivateKey.Algorithm() != algorithm || keys.PublicKey.Algorithm() != algorithm {
+				t.Fatalf("generated keys report %s/%s", keys.PrivateKey.Algorithm(), keys.PublicKey.Algorithm())
+			}
+
+			encodedPrivate, err := keys.PrivateKey.ToBase64()
+			if err != nil {
+				t.Fatal(err)
+			}
+			decodedPrivate := &IPrivateKey{}
+			if err = decodedPrivate.FromBase64(encodedPrivate); err != nil {
+				t.Fatal(err)
+			}
+			assertSameKey(t, keys.PrivateKey, decodedPrivate)
+
+			encodedPublic, err := keys.PublicKey.ToBase64()
+			if err != nil {
+				t.Fatal(err)
+			}
+			decodedPublic := &IPublicKey{}
+			if err = decodedPublic.FromBase64(encodedPublic); err != nil {
+				t.Fatal(err)
+			}
+			if decodedPublic.Algorithm() != algorithm || decodedPublic.KeyPairID != keys.ID {
+				t.Fatalf("decoded public key reports %s/%q", decodedPublic.Algorithm(), decodedPublic.KeyPairID)
+			}
+
+			pemBytes, err := keys.PrivateKey.ToPEM()
+			if err != nil {
+				t.Fatal(err)
+			}
+			fromPEM := &IPrivateKey{}
+			if err = fromPEM.FromPEM(pemBytes, keys.ID); err != nil {
+				t.Fatal(err)
+			}
+			assertSameKey(t, keys.PrivateKey, fromPEM)
+
+			jwk, err := keys.PrivateKey.ToJWK()
+			if err != nil {
+				t.Fatal(err)
+			}
+			fromJWK := &IPrivateKey{}
+			if err = fromJWK.FromJWK(jwk); err != nil {
+				t.Fatal(err)
+			}
+			assertSameKey(t, keys.PrivateKey, fromJWK)
+
+			encrypted, err := keys.PrivateKey.ToEncryptedBase64WithParameters([]byte("passphrase"), fastScryptParameters)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fromEncrypted := &IPrivateKey{}
+			if err = fromEncrypted.FromEncryptedBase64(encrypted, []byte("passphrase")); err != nil {
+				t.Fatal(err)
+			}
+			assertSameKey(t, keys.PrivateKey, fromEncrypted)
+
+			signature, err := keys.PrivateKey.SignRequest(request, nil, "2.0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			parsed, err := ParseSignature(signature)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (algorithm == DefaultAlgorithm) != (parsed.Algorithm == "") {
+				t.Fatalf("unexpected algorithm field %q for %s", parsed.Algorithm, algorithm)
+			}
+			if valid, err := keys.PublicKey.VerifyRequestSignature(request, signature, 60); !valid || err != nil {
+				t.Fatalf("request signature did not verify: %v", err)
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsAlgorithmMismatch(t *testing.T) {
+	p256Keys, err := GenerateIKeysWithAlgorithm(AlgorithmP256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature, err := p256Keys.PrivateKey.SignMessage("message", "1.2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p384Keys, err := GenerateIKeysWithAlgorithm(AlgorithmP384)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p384Keys.PublicKey.KeyPairID = p256Keys.ID
+	if valid, err := p384Keys.PublicKey.VerifySignature("message", signature, 60); valid || !errors.Is(err, ErrAlgorithmMismatch) {
+		t.Fatalf("expected algorithm mismatch, got %v, %v", valid, err)
+	}
+
+	if _, err = p256Keys.PrivateKey.SignMessage("message", "1.0"); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Fatalf("expected version 1.0 to be limited to P-521, got %v", err)
+	}
+	if _, err = ParseAlgorithm("rsa"); !errors.Is(err, ErrUnsupportedAlgorithm) {
+		t.Fatalf("expected unknown algorithm to be rejected, got %v", err)
+	}
+}
+
+type keyIDOnlySigner struct {
+	privateKey *IPrivateKey
+}
+
+func (s keyIDOnlySigner) KeyID() string { return s.privateKey.KeyPairID }
+
+func (s keyIDOnlySigner) Sign(digest []byte) ([]byte, error) { return s.privateKey.Sign(digest) }
+
+func TestSignWithSigner(t *testing.T) {
+	keys := mustGenerateIKeys(t)
+	signer := keyIDOnlySigner{privateKey: keys.PrivateKey}
+	if SignerAlgorithm(signer) != AlgorithmP521 {
+		t.Fatalf("signers without an algorithm should default to %s", AlgorithmP521)
+	}
+	request := &SignableRequest{Method: "GET", Path: "/", Host: "api.infuzu.com"}
+	signature, err := SignRequestWithSigner(signer, request, nil, "2.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if valid, err := keys.PublicKey.VerifyRequestSignature(request, signature, 60); !valid || err != nil {
+		t.Fatalf("signer signature did not verify: %v", err)
+	}
+	if _, err = SignMessageWithSigner(nil, "message", "1.2"); !errors.Is(err, ErrNilSigner) {
+		t.Fatalf("expected nil signer error, got %v", err)
+	}
+	if _, err = (&IPrivateKey{KeyPairID: "empty"}).Sign([]byte("digest")); !errors.Is(err, ErrInvalidPrivateKey) {
+		t.Fatalf("expected an empty key to be refused, got %v", err)
+	}
+}
+
+func TestRequestSignatureBindsRequest(t *testing.T) {
+	keys := mustGenerateIKeys(t)
+	newRequest := func() *SignableRequest {
+		return &SignableRequest{
+			Method:   "POST",
+			Path:     "/api/items/",
+			RawQuery: "b=2&a=1",
+			Host:     "api.infuzu.com",
+			Header:   http.Header{"Content-Type": {"application/json"}, "X-Unsigned": {"1"}},
+			Body:     []byte(`{"name":"item"}`),
+		}
+	}
+	signature, err := keys.PrivateKey.SignRequest(newRequest(), []string{"Content-Type", "Host"}, "2.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	equivalent := newRequest()
+	equivalent.Method = "post"
+	equivalent.RawQuery = "a=1&b=2"
+	equivalent.Header.Set("X-Unsigned", "2")
+	if valid, err := keys.PublicKey.VerifyRequestSignature(equivalent, signature, 60); !valid || err != nil {
+		t.Fatalf("canonically equivalent request did not verify: %v", err)
+	}
+
+	tamperings := map[string]func(r *SignableRequest){
+		"method":        func(r *SignableRequest) { r.Method = "DELETE" },
+		"path":          func(r *SignableRequest) { r.Path = "/api/other/" },
+		"query value":   func(r *SignableRequest) { r.RawQuery = "b=2&a=3" },
+		"added query":   func(r *SignableRequest) { r.RawQuery += "&c=1" },
+		"signed header": func(r *SignableRequest) { r.Header.Set("Content-Type", "text/plain") },
+		"host":          func(r *SignableRequest) { r.Host = "evil.example.com" },
+		"body":          func(r *SignableRequest) { r.Body = []byte(`{"name":"other"}`) },
+	}
+	for name, tamper := range tamperings {
+		t.Run(name, func(t *testing.T) {
+			request := newRequest()
+			tamper(request)
+			valid, err := keys.PublicKey.VerifyRequestSignature(request, signature, 60)
+			if valid || !errors.Is(err, ErrInvalidSignature) {
+				t.Fatalf("expected tampered request to fail verification, got %v, %v", valid, err)
+			}
+		})
+	}
+}
+
+func rewriteSignature(t *testing.T, signature string, rewrite func(raw []byte) []byte) string {
+	t.Helper()
+	decoded, err := base64.URLEncoding.DecodeString(signature)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]interface{}
+	if err = json.Unmarshal(decoded, &fields); err != nil {
+		t.Fatal(err)
+	}
+	raw, err := base64.URLEncoding.DecodeString(fields["s"].(string))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields["s"] = base64.URLEncoding.EncodeToString(rewrite(raw))
+	encoded, err := json.Marshal(fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.URLEncoding.EncodeToString(encoded)
+}
+
+func TestSignatureReplayIdentityResistsMalleability(t *testing.T) {
+	keys := mustGenerateIKeys(t)
+	signature, err := keys.PrivateKey.SignMessage("pay 10", "1.2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	identity, _, err := SignatureReplayIdentity(signature)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	malleated := rewriteSignature(t, signature, func(raw []byte) []byte {
+		var esig EcdsaSignature
+		if _, err := asn1.Unmarshal(raw, &esig); err != nil {
+			t.Fatal(err)
+		}
+		esig.S = new(big.Int).Sub(elliptic.P521().Params().N, esig.S)
+		encoded, err := asn1.Marshal(esig)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return encoded
+	})
+	if malleated == signature {
+		t.Fatal("malleated signature should differ from the original")
+	}
+	if valid, err := keys.PublicKey.VerifySignature("pay 10", malleated, 60); !valid || err != nil {
+		t.Fatalf("the N-s form of an ECDSA signature is expected to verify: %v", err)
+	}
+	malleatedIdentity, _, err := SignatureReplayIdentity(malleated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if malleatedIdentity != identity {
+		t.Fatal("malleated signature was given a new replay identity")
+	}
+
+	padded := rewriteSignature(t, signature, func(raw []byte) []byte {
+		return append(raw, 0)
+	})
+	if valid, err := keys.PublicKey.VerifySignature("pay 10", padded, 60); valid || !errors.Is(err, ErrMalformedSignature) {
+		t.Fatalf("expected trailing bytes to be rejected, got %v, %v", valid, err)
+	}
+	if _, _, err = SignatureReplayIdentity(padded); !errors.Is(err, ErrMalformedSignature) {
+		t.Fatalf("expected trailing bytes to have no replay identity, got %v", err)
+	}
+}
+
+func TestVerifySignatureErrors(t *testing.T) {
+	keys := mustGenerateIKeys(t)
+	signature, err := keys.PrivateKey.SignMessage("message", "1.2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rewriteFields := func(rewrite func(fields map[string]interface{})) string {
+		decoded, err := base64.URLEncoding.DecodeString(signature)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var fields map[string]interface{}
+		if err = json.Unmarshal(decoded, &fields); err != nil {
+			t.Fatal(err)
+		}
+		rewrite(fields)
+		encoded, err := json.Marshal(fields)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return base64.URLEncoding.EncodeToString(encoded)
+	}
+
+	cases := map[string]struct {
+		publicKey *IPublicKey
+		message   string
+		signature string
+		expected  []error
+	}{
+		"not base64": {keys.PublicKey, "message", "%%%", []error{ErrMalformedSignature}},
+		"missing key id": {keys.PublicKey, "message", rewriteFields(func(fields map[string]interface{}) {
+			delete(fields, "i")
+		}), []error{ErrMalformedSignature, ErrKeyIDNotFound}},
+		"unknown version": {keys.PublicKey, "message", rewriteFields(func(fields map[string]interface{}) {
+			fields["v"] = "9.9"
+		}), []error{ErrUnsupportedVersion}},
+		"expired": {keys.PublicKey, "message", rewriteFields(func(fields map[string]interface{}) {
+			fields["t"] = time.Now().Add(-time.Hour).Unix()
+		}), []error{ErrSignatureExpired}},
+		"other key":       {mustGenerateIKeys(t).PublicKey, "message", signature, []error{ErrKeyIDMismatch}},
+		"altered message": {keys.PublicKey, "other message", signature, []error{ErrInvalidSignature}},
+	}
+	for name, testCase := range cases {
+		t.Run(name, func(t *testing.T) {
+			valid, err := testCase.publicKey.VerifySignature(testCase.message, testCase.signature, 60)
+			if valid {
+				t.Fatal("signature was accepted")
+			}
+			for _, expected := range testCase.expected {
+				if !errors.Is(err, expected) {
+					t.Fatalf("expected %v, got %v", expected, err)
+				}
+			}
+		})
+	}
+}
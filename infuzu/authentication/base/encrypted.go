@@ -0,0 +1,217 @@
+package infuzu
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	privateKeyFormatEncrypted = "encrypted"
+	encryptionKDFScrypt       = "scrypt"
+	encryptionCipherAESGCM    = "aes-256-gcm"
+	encryptionSaltSize        = 16
+	encryptionKeySize         = 32
+)
+
+type ScryptParameters struct {
+	N int `json:"n"`
+	R int `json:"r"`
+	P int `json:"p"`
+}
+
+var DefaultScryptParameters = ScryptParameters{N: 1 << 15, R: 8, P: 1}
+
+// maxScryptMemory bounds the 128·N·r bytes scrypt allocates, so a crafted
+// envelope cannot exhaust memory before its passphrase is checked.
+const maxScryptMemory = 256 << 20
+
+func (p ScryptParameters) validate() error {
+	if p.N < 1<<10 || p.N&(p.N-1) != 0 {
+		return fmt.Errorf("%w: scrypt cost %d is not a power of two of at least 2^10", ErrInvalidPrivateKey, p.N)
+	}
+	if p.R < 1 || p.P < 1 || p.P > 16 {
+		return fmt.Errorf("%w: scrypt parameters r=%d p=%d are out of range", ErrInvalidPrivateKey, p.R, p.P)
+	}
+	if p.N > maxScryptMemory/128/p.R {
+		return fmt.Errorf("%w: scrypt parameters N=%d r=%d need more than 256 MiB", ErrInvalidPrivateKey, p.N, p.R)
+	}
+	return nil
+}
+
+type encryptedKeyDerivation struct {
+	Name string `json:"name"`
+	ScryptParameters
+	Salt string `json:"salt"`
+}
+
+type encryptedKeyCipher struct {
+	Name  string `json:"name"`
+	Nonce string `json:"nonce"`
+}
+
+type encryptedPrivateKeyHeader struct {
+	Version    string                 `json:"v"`
+	Format     string                 `json:"f"`
+	KeyPairID  string                 `json:"i"`
+	Derivation encryptedKeyDerivation `json:"kdf"`
+	Cipher     encryptedKeyCipher     `json:"cipher"`
+}
+
+type encryptedPrivateKey struct {
+	encryptedPrivateKeyHeader
+	Data string `json:"data"`
+}
+
+func (sk *IPrivateKey) ToEncryptedBase64(passphrase []byte) (string, error) {
+	return sk.ToEncryptedBase64WithParameters(passphrase, DefaultScryptParameters)
+}
+
+func (sk *IPrivateKey) ToEncryptedBase64WithParameters(passphrase []byte, parameters ScryptParameters) (string, error) {
+	if len(passphrase) == 0 {
+		return "", fmt.Errorf("%w: passphrase cannot be empty", ErrInvalidPrivateKey)
+	}
+	if err := parameters.validate(); err != nil {
+		return "", err
+	}
+	plaintext, err := sk.ToBase64()
+	if err != nil {
+		return "", err
+	}
+
+	salt := make([]byte, encryptionSaltSize)
+	if _, err = rand.Read(salt); err != nil {
+		return "", err
+	}
+	var aead cipher.AEAD
+	aead, err = newPrivateKeyAEAD(passphrase, salt, parameters)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	envelope := encryptedPrivateKey{
+		encryptedPrivateKeyHeader: encryptedPrivateKeyHeader{
+			Version:   privateKeyEnvelopeVersion,
+			Format:    privateKeyFormatEncrypted,
+			KeyPairID: sk.KeyPairID,
+			Derivation: encryptedKeyDerivation{
+				Name:             encryptionKDFScrypt,
+				ScryptParameters: parameters,
+				Salt:             base64.URLEncoding.EncodeToString(salt),
+			},
+			Cipher: encryptedKeyCipher{
+				Name:  encryptionCipherAESGCM,
+				Nonce: base64.URLEncoding.EncodeToString(nonce),
+			},
+		},
+	}
+	var additionalData []byte
+	additionalData, err = json.Marshal(envelope.encryptedPrivateKeyHeader)
+	if err != nil {
+		return "", err
+	}
+	envelope.Data = base64.URLEncoding.EncodeToString(aead.Seal(nil, nonce, []byte(plaintext), additionalData))
+
+	var envelopeJson []byte
+	envelopeJson, err = json.Marshal(envelope)
+	if err != nil {
+		return "", err
+	}
+	return base64.URLEncoding.EncodeToString(envelopeJson), nil
+}
+
+func (sk *IPrivateKey) FromEncryptedBase64(encoded string, passphrase []byte) error {
+	decodedBytes, err := base64.URLEncoding.DecodeString(encoded)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPrivateKey, err)
+	}
+	var envelope encryptedPrivateKey
+	if err = json.Unmarshal(decodedBytes, &envelope); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPrivateKey, err)
+	}
+	if envelope.Version != privateKeyEnvelopeVersion || envelope.Format != privateKeyFormatEncrypted {
+		return fmt.Errorf("%w: key is not an encrypted private key", ErrUnsupportedKeyFormat)
+	}
+	if envelope.Derivation.Name != encryptionKDFScrypt || envelope.Cipher.Name != encryptionCipherAESGCM {
+		return fmt.Errorf(
+			"%w: %s with %s", ErrUnsupportedKeyFormat, envelope.Derivation.Name, envelope.Cipher.Name,
+		)
+	}
+	if err = envelope.Derivation.ScryptParameters.validate(); err != nil {
+		return err
+	}
+
+	var salt, nonce, ciphertext []byte
+	if salt, err = base64.URLEncoding.DecodeString(envelope.Derivation.Salt); err != nil {
+		return fmt.Errorf("%w: salt: %v", ErrInvalidPrivateKey, err)
+	}
+	if nonce, err = base64.URLEncoding.DecodeString(envelope.Cipher.Nonce); err != nil {
+		return fmt.Errorf("%w: nonce: %v", ErrInvalidPrivateKey, err)
+	}
+	if ciphertext, err = base64.URLEncoding.DecodeString(envelope.Data); err != nil {
+		return fmt.Errorf("%w: data: %v", ErrInvalidPrivateKey, err)
+	}
+	var aead cipher.AEAD
+	aead, err = newPrivateKeyAEAD(passphrase, salt, envelope.Derivation.ScryptParameters)
+	if err != nil {
+		return err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return fmt.Errorf("%w: nonce is %d bytes long", ErrInvalidPrivateKey, len(nonce))
+	}
+	var additionalData []byte
+	additionalData, err = json.Marshal(envelope.encryptedPrivateKeyHeader)
+	if err != nil {
+		return err
+	}
+	var plaintext []byte
+	plaintext, err = aead.Open(nil, nonce, ciphertext, additionalData)
+	if err != nil {
+		return ErrIncorrectPassphrase
+	}
+
+	decrypted := &IPrivateKey{}
+	if err = decrypted.FromBase64(string(plaintext)); err != nil {
+		return err
+	}
+	if decrypted.KeyPairID != envelope.KeyPairID {
+		return fmt.Errorf("%w: encrypted key pair id does not match its envelope", ErrInvalidPrivateKey)
+	}
+	*sk = *decrypted
+	return nil
+}
+
+func IsEncryptedPrivateKey(encoded string) bool {
+	decodedBytes, err := base64.URLEncoding.DecodeString(encoded)
+	if err != nil {
+		return false
+	}
+	var header struct {
+		Format string `json:"f"`
+	}
+	return json.Unmarshal(decodedBytes, &header) == nil && header.Format == privateKeyFormatEncrypted
+}
+
+func newPrivateKeyAEAD(passphrase []byte, salt []byte, parameters ScryptParameters) (cipher.AEAD, error) {
+	if len(salt) < encryptionSaltSize {
+		return nil, fmt.Errorf("%w: salt is %d bytes long", ErrInvalidPrivateKey, len(salt))
+	}
+	key, err := scrypt.Key(passphrase, salt, parameters.N, parameters.R, parameters.P, encryptionKeySize)
+	if err != nil {
+		return nil, err
+	}
+	var block cipher.Block
+	block, err = aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,25 @@
+package infuzu
+
+import (
+	"errors"
+	utils "github.com/infuzu/infuzu-go-sdk/infuzu/utils"
+)
+
+type ParsedSignature = utils.ParsedSignature
+
+var (
+	ErrMalformedSignature   = utils.ErrMalformedSignature
+	ErrUnsupportedVersion   = utils.ErrUnsupportedVersion
+	ErrKeyIDNotFound        = utils.ErrKeyIDNotFound
+	ErrSignatureExpired     = errors.New("infuzu/authentication/base.go signature has expired")
+	ErrKeyIDMismatch        = errors.New("infuzu/authentication/base.go signature key id does not match the public key")
+	ErrInvalidSignature     = errors.New("infuzu/authentication/base.go signature does not match the signed content")
+	ErrInvalidPublicKey     = errors.New("infuzu/authentication/base.go public key is invalid")
+	ErrInvalidPrivateKey    = errors.New("infuzu/authentication/base.go private key is invalid")
+	ErrEncryptedPrivateKey  = errors.New("infuzu/authentication/base.go private key is encrypted and requires a passphrase")
+	ErrIncorrectPassphrase  = errors.New("infuzu/authentication/base.go passphrase is incorrect or the encrypted key is corrupted")
+	ErrUnsupportedAlgorithm = errors.New("infuzu/authentication/base.go key algorithm is not supported")
+	ErrAlgorithmMismatch    = errors.New("infuzu/authentication/base.go signature algorithm does not match the public key")
+	ErrUnsupportedKeyFormat = errors.New("infuzu/authentication/base.go key encoding format is not supported")
+	ErrNilSigner            = errors.New("infuzu/authentication/base.go signer cannot be nil")
+)
@@ -0,0 +1,182 @@
+package infuzu
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+)
+
+const (
+	jwkKeyTypeEC    = "EC"
+	jwkKeyTypeOKP   = "OKP"
+	jwkUseSignature = "sig"
+)
+
+type JWK struct {
+	KeyType string `json:"kty"`
+	Curve   string `json:"crv"`
+	X       string `json:"x"`
+	Y       string `json:"y,omitempty"`
+	D       string `json:"d,omitempty"`
+	KeyID   string `json:"kid,omitempty"`
+	Use     string `json:"use,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+func (pk *IPublicKey) ToJWK() (*JWK, error) {
+	if err := pk.validate(); err != nil {
+		return nil, err
+	}
+	algorithm := pk.Algorithm()
+	jwk := &JWK{
+		Curve: algorithm.jwkCurve(),
+		KeyID: pk.KeyPairID,
+		Use:   jwkUseSignature,
+	}
+	if algorithm == AlgorithmEd25519 {
+		jwk.KeyType = jwkKeyTypeOKP
+		jwk.X = base64.RawURLEncoding.EncodeToString(pk.Ed25519PublicKey)
+		return jwk, nil
+	}
+	size := coordinateSize(pk.PublicKey.Curve)
+	jwk.KeyType = jwkKeyTypeEC
+	jwk.X = base64.RawURLEncoding.EncodeToString(pk.PublicKey.X.FillBytes(make([]byte, size)))
+	jwk.Y = base64.RawURLEncoding.EncodeToString(pk.PublicKey.Y.FillBytes(make([]byte, size)))
+	return jwk, nil
+}
+
+func (pk *IPublicKey) FromJWK(jwk *JWK) error {
+	publicKey, err := jwk.publicKey()
+	if err != nil {
+		return err
+	}
+	*pk = *publicKey
+	return nil
+}
+
+func (sk *IPrivateKey) ToJWK() (*JWK, error) {
+	if err := sk.validate(); err != nil {
+		return nil, err
+	}
+	jwk, err := sk.PublicKey().ToJWK()
+	if err != nil {
+		return nil, err
+	}
+	if sk.Ed25519PrivateKey != nil {
+		jwk.D = base64.RawURLEncoding.EncodeToString(sk.Ed25519PrivateKey.Seed())
+	} else {
+		scalar := sk.PrivateKey.D.FillBytes(make([]byte, coordinateSize(sk.PrivateKey.Curve)))
+		jwk.D = base64.RawURLEncoding.EncodeToString(scalar)
+	}
+	return jwk, nil
+}
+
+func (sk *IPrivateKey) FromJWK(jwk *JWK) error {
+	publicKey, err := jwk.publicKey()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidPrivateKey, err)
+	}
+	var d []byte
+	var privateKey *IPrivateKey
+	if publicKey.Ed25519PublicKey != nil {
+		d, err = decodeJWKMember(jwk.D, "d", ed25519.SeedSize)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidPrivateKey, err)
+		}
+		privateKey = &IPrivateKey{Ed25519PrivateKey: ed25519.NewKeyFromSeed(d)}
+		if !privateKey.PublicKey().Ed25519PublicKey.Equal(publicKey.Ed25519PublicKey) {
+			return fmt.Errorf("%w: jwk members d and x do not match", ErrInvalidPrivateKey)
+		}
+	} else {
+		d, err = decodeJWKMember(jwk.D, "d", coordinateSize(publicKey.PublicKey.Curve))
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidPrivateKey, err)
+		}
+		privateKey = &IPrivateKey{PrivateKey: &ecdsa.PrivateKey{PublicKey: *publicKey.PublicKey, D: new(big.Int).SetBytes(d)}}
+	}
+	if err = privateKey.validate(); err != nil {
+		return err
+	}
+	privateKey.KeyPairID = jwk.KeyID
+	*sk = *privateKey
+	return nil
+}
+
+func NewJWKS(publicKeys ...*IPublicKey) (*JWKS, error) {
+	jwks := &JWKS{Keys: make([]JWK, 0, len(publicKeys))}
+	for _, publicKey := range publicKeys {
+		jwk, err := publicKey.ToJWK()
+		if err != nil {
+			return nil, err
+		}
+		jwks.Keys = append(jwks.Keys, *jwk)
+	}
+	return jwks, nil
+}
+
+func (s *JWKS) PublicKey(keyPairID string) (*IPublicKey, error) {
+	for i := range s.Keys {
+		if s.Keys[i].KeyID != keyPairID {
+			continue
+		}
+		publicKey := &IPublicKey{}
+		if err := publicKey.FromJWK(&s.Keys[i]); err != nil {
+			return nil, err
+		}
+		return publicKey, nil
+	}
+	return nil, fmt.Errorf("%w: no key with id %q in key set", ErrInvalidPublicKey, keyPairID)
+}
+
+func (jwk *JWK) publicKey() (*IPublicKey, error) {
+	if jwk == nil {
+		return nil, fmt.Errorf("%w: jwk cannot be nil", ErrInvalidPublicKey)
+	}
+	algorithm, err := algorithmForJWKCurve(jwk.Curve)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPublicKey, err)
+	}
+	publicKey := &IPublicKey{KeyPairID: jwk.KeyID}
+	if algorithm == AlgorithmEd25519 {
+		if jwk.KeyType != jwkKeyTypeOKP {
+			return nil, fmt.Errorf("%w: curve %s requires key type %s", ErrInvalidPublicKey, jwk.Curve, jwkKeyTypeOKP)
+		}
+		publicKey.Ed25519PublicKey, err = decodeJWKMember(jwk.X, "x", ed25519.PublicKeySize)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPublicKey, err)
+		}
+	} else {
+		if jwk.KeyType != jwkKeyTypeEC {
+			return nil, fmt.Errorf("%w: curve %s requires key type %s", ErrInvalidPublicKey, jwk.Curve, jwkKeyTypeEC)
+		}
+		curve := algorithm.curve()
+		var x, y []byte
+		if x, err = decodeJWKMember(jwk.X, "x", coordinateSize(curve)); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPublicKey, err)
+		}
+		if y, err = decodeJWKMember(jwk.Y, "y", coordinateSize(curve)); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPublicKey, err)
+		}
+		publicKey.PublicKey = &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+	}
+	if err = publicKey.validate(); err != nil {
+		return nil, err
+	}
+	return publicKey, nil
+}
+
+func decodeJWKMember(encoded string, name string, size int) ([]byte, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("jwk member %q is not base64url: %v", name, err)
+	}
+	if len(decoded) != size {
+		return nil, fmt.Errorf("jwk member %q is %d bytes long", name, len(decoded))
+	}
+	return decoded, nil
+}
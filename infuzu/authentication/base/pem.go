@@ -0,0 +1,110 @@
+package infuzu
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+)
+
+const (
+	pemTypePKCS8PrivateKey = "PRIVATE KEY"
+	pemTypeSEC1PrivateKey  = "EC PRIVATE KEY"
+	pemTypePublicKey       = "PUBLIC KEY"
+)
+
+func (sk *IPrivateKey) ToPEM() ([]byte, error) {
+	if err := sk.validate(); err != nil {
+		return nil, err
+	}
+	privateKeyBytes, err := x509.MarshalPKCS8PrivateKey(sk.cryptoKey())
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: pemTypePKCS8PrivateKey, Bytes: privateKeyBytes}), nil
+}
+
+func (sk *IPrivateKey) ToSEC1PEM() ([]byte, error) {
+	if err := sk.validate(); err != nil {
+		return nil, err
+	}
+	if sk.PrivateKey == nil {
+		return nil, fmt.Errorf("%w: SEC 1 only encodes ECDSA keys", ErrUnsupportedKeyFormat)
+	}
+	privateKeyBytes, err := x509.MarshalECPrivateKey(sk.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: pemTypeSEC1PrivateKey, Bytes: privateKeyBytes}), nil
+}
+
+func (sk *IPrivateKey) FromPEM(data []byte, keyPairID string) error {
+	block, err := decodePEMBlock(data, ErrInvalidPrivateKey)
+	if err != nil {
+		return err
+	}
+	var parsed interface{}
+	switch block.Type {
+	case pemTypeSEC1PrivateKey:
+		parsed, err = x509.ParseECPrivateKey(block.Bytes)
+	case pemTypePKCS8PrivateKey:
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return fmt.Errorf("%w: unexpected PEM block %q", ErrInvalidPrivateKey, block.Type)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPrivateKey, err)
+	}
+	var privateKey *IPrivateKey
+	privateKey, err = privateKeyFromCrypto(parsed)
+	if err != nil {
+		return err
+	}
+	privateKey.KeyPairID = keyPairID
+	*sk = *privateKey
+	return nil
+}
+
+func (pk *IPublicKey) ToPEM() ([]byte, error) {
+	if err := pk.validate(); err != nil {
+		return nil, err
+	}
+	publicKeyBytes, err := x509.MarshalPKIXPublicKey(pk.cryptoKey())
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: pemTypePublicKey, Bytes: publicKeyBytes}), nil
+}
+
+func (pk *IPublicKey) FromPEM(data []byte, keyPairID string) error {
+	block, err := decodePEMBlock(data, ErrInvalidPublicKey)
+	if err != nil {
+		return err
+	}
+	if block.Type != pemTypePublicKey {
+		return fmt.Errorf("%w: unexpected PEM block %q", ErrInvalidPublicKey, block.Type)
+	}
+	var parsed interface{}
+	parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPublicKey, err)
+	}
+	var publicKey *IPublicKey
+	publicKey, err = publicKeyFromCrypto(parsed)
+	if err != nil {
+		return err
+	}
+	publicKey.KeyPairID = keyPairID
+	*pk = *publicKey
+	return nil
+}
+
+func decodePEMBlock(data []byte, invalidKey error) (*pem.Block, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%w: no PEM block found", invalidKey)
+	}
+	if len(block.Headers) != 0 {
+		return nil, fmt.Errorf("%w: encrypted or annotated PEM blocks are not supported", invalidKey)
+	}
+	return block, nil
+}
@@ -0,0 +1,117 @@
+package infuzu
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+type SignableRequest struct {
+	Method   string
+	Path     string
+	RawQuery string
+	Host     string
+	Header   http.Header
+	Body     []byte
+}
+
+func NewSignableRequest(req *http.Request, body []byte) *SignableRequest {
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	return &SignableRequest{
+		Method:   req.Method,
+		Path:     path,
+		RawQuery: req.URL.RawQuery,
+		Host:     host,
+		Header:   req.Header,
+		Body:     body,
+	}
+}
+
+func (r *SignableRequest) canonicalForm(
+	keyPairID string, timestamp int64, nonce string, signedHeaders []string,
+) (map[string]interface{}, error) {
+	query, err := canonicalQuery(r.RawQuery)
+	if err != nil {
+		return nil, err
+	}
+	path := r.Path
+	if path == "" {
+		path = "/"
+	}
+	headers := make(map[string]interface{}, len(signedHeaders))
+	for _, name := range signedHeaders {
+		headers[name] = r.headerValue(name)
+	}
+	bodyDigest := sha256.Sum256(r.Body)
+	canonical := map[string]interface{}{
+		"i": keyPairID,
+		"t": timestamp,
+		"m": strings.ToUpper(r.Method),
+		"p": path,
+		"q": query,
+		"h": headers,
+		"d": base64.URLEncoding.EncodeToString(bodyDigest[:]),
+	}
+	if nonce != "" {
+		canonical["n"] = nonce
+	}
+	return canonical, nil
+}
+
+func (r *SignableRequest) headerValue(name string) string {
+	if name == "host" {
+		return strings.ToLower(r.Host)
+	}
+	values := r.Header.Values(name)
+	trimmed := make([]string, len(values))
+	for i, value := range values {
+		trimmed[i] = strings.TrimSpace(value)
+	}
+	return strings.Join(trimmed, ",")
+}
+
+func canonicalQuery(rawQuery string) (string, error) {
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return "", err
+	}
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(values))
+	for _, key := range keys {
+		keyValues := append([]string(nil), values[key]...)
+		sort.Strings(keyValues)
+		for _, value := range keyValues {
+			pairs = append(pairs, url.QueryEscape(key)+"="+url.QueryEscape(value))
+		}
+	}
+	return strings.Join(pairs, "&"), nil
+}
+
+func normalizeSignedHeaders(signedHeaders []string) []string {
+	seen := make(map[string]bool, len(signedHeaders))
+	normalized := make([]string, 0, len(signedHeaders))
+	for _, header := range signedHeaders {
+		name := strings.ToLower(strings.TrimSpace(header))
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		normalized = append(normalized, name)
+	}
+	sort.Strings(normalized)
+	return normalized
+}
@@ -0,0 +1,58 @@
+package infuzu
+
+import "context"
+
+// Signer signs on behalf of a key pair without exposing its private key, so
+// keys can be held by another process such as a signing agent. Sign receives
+// Algorithm.Digest of the signed content and returns an ASN.1 DER signature for
+// ECDSA keys or a raw 64-byte signature for Ed25519 keys.
+type Signer interface {
+	KeyID() string
+	Sign(digest []byte) ([]byte, error)
+}
+
+// AlgorithmSigner is implemented by signers whose key is not P-521.
+type AlgorithmSigner interface {
+	Signer
+	Algorithm() Algorithm
+}
+
+// ContextSigner is implemented by signers that do I/O, such as a signing agent,
+// so signing can stop when the request it belongs to is cancelled.
+type ContextSigner interface {
+	Signer
+	SignContext(ctx context.Context, digest []byte) ([]byte, error)
+}
+
+type contextBoundSigner struct {
+	ContextSigner
+	ctx context.Context
+}
+
+func (s contextBoundSigner) Sign(digest []byte) ([]byte, error) {
+	return s.SignContext(s.ctx, digest)
+}
+
+func (s contextBoundSigner) Algorithm() Algorithm {
+	return SignerAlgorithm(s.ContextSigner)
+}
+
+// BindSignerContext returns a Signer whose Sign uses ctx when signer is a
+// ContextSigner, and signer itself otherwise.
+func BindSignerContext(ctx context.Context, signer Signer) Signer {
+	if contextSigner, ok := signer.(ContextSigner); ok {
+		return contextBoundSigner{ContextSigner: contextSigner, ctx: ctx}
+	}
+	return signer
+}
+
+func SignerAlgorithm(signer Signer) Algorithm {
+	if algorithmSigner, ok := signer.(AlgorithmSigner); ok {
+		if algorithm := algorithmSigner.Algorithm(); algorithm != "" {
+			return algorithm
+		}
+	}
+	return DefaultAlgorithm
+}
+
+var _ AlgorithmSigner = (*IPrivateKey)(nil)
@@ -0,0 +1,81 @@
+package infuzu
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+)
+
+type ReplayStore interface {
+	MarkSeen(ctx context.Context, key string, expiresAt time.Time) (bool, error)
+}
+
+type replayEntry struct {
+	key       string
+	expiresAt time.Time
+}
+
+type replayHeap []*replayEntry
+
+func (h replayHeap) Len() int           { return len(h) }
+func (h replayHeap) Less(i, j int) bool { return h[i].expiresAt.Before(h[j].expiresAt) }
+func (h replayHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *replayHeap) Push(x interface{}) {
+	*h = append(*h, x.(*replayEntry))
+}
+
+func (h *replayHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	entry := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return entry
+}
+
+type MemoryReplayStore struct {
+	seen   map[string]time.Time
+	expiry replayHeap
+	mutex  sync.Mutex
+}
+
+func NewMemoryReplayStore() *MemoryReplayStore {
+	return &MemoryReplayStore{
+		seen: make(map[string]time.Time),
+	}
+}
+
+func (rs *MemoryReplayStore) MarkSeen(_ context.Context, key string, expiresAt time.Time) (bool, error) {
+	rs.mutex.Lock()
+	defer rs.mutex.Unlock()
+
+	currentTime := time.Now()
+	rs.cleanup(currentTime)
+	if !expiresAt.After(currentTime) {
+		return false, nil
+	}
+	if _, exists := rs.seen[key]; exists {
+		return false, nil
+	}
+	rs.seen[key] = expiresAt
+	heap.Push(&rs.expiry, &replayEntry{key: key, expiresAt: expiresAt})
+	return true, nil
+}
+
+func (rs *MemoryReplayStore) Len() int {
+	rs.mutex.Lock()
+	defer rs.mutex.Unlock()
+	rs.cleanup(time.Now())
+	return len(rs.seen)
+}
+
+func (rs *MemoryReplayStore) cleanup(currentTime time.Time) {
+	for rs.expiry.Len() > 0 && !rs.expiry[0].expiresAt.After(currentTime) {
+		entry := heap.Pop(&rs.expiry).(*replayEntry)
+		if expiresAt, exists := rs.seen[entry.key]; exists && !expiresAt.After(currentTime) {
+			delete(rs.seen, entry.key)
+		}
+	}
+}
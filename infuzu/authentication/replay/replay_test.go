@@ -0,0 +1,81 @@
+package infuzu
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMemoryReplayStoreMarkSeen(t *testing.T) {
+	store := NewMemoryReplayStore()
+	ctx := context.Background()
+	expiresAt := time.Now().Add(time.Minute)
+
+	if fresh, err := store.MarkSeen(ctx, "a", expiresAt); !fresh || err != nil {
+		t.Fatalf("first use should be fresh, got %v, %v", fresh, err)
+	}
+	if fresh, _ := store.MarkSeen(ctx, "a", expiresAt); fresh {
+		t.Fatal("second use of the same key should be rejected")
+	}
+	if fresh, _ := store.MarkSeen(ctx, "b", expiresAt); !fresh {
+		t.Fatal("a different key should be fresh")
+	}
+	if fresh, _ := store.MarkSeen(ctx, "c", time.Now().Add(-time.Second)); fresh {
+		t.Fatal("a key whose window has already closed should be rejected")
+	}
+	if store.Len() != 2 {
+		t.Fatalf("expected 2 tracked keys, got %d", store.Len())
+	}
+}
+
+func TestMemoryReplayStoreExpiry(t *testing.T) {
+	store := NewMemoryReplayStore()
+	ctx := context.Background()
+	currentTime := time.Now()
+
+	// Pushed out of order so eviction has to follow the heap, not insertion order.
+	for i, lifetime := range []time.Duration{time.Minute, 40 * time.Millisecond, 20 * time.Millisecond, time.Minute} {
+		if fresh, _ := store.MarkSeen(ctx, fmt.Sprint(i), currentTime.Add(lifetime)); !fresh {
+			t.Fatalf("key %d should be fresh", i)
+		}
+	}
+	time.Sleep(30 * time.Millisecond)
+	if store.Len() != 3 {
+		t.Fatalf("expected the 20ms key to be evicted, %d keys remain", store.Len())
+	}
+	time.Sleep(30 * time.Millisecond)
+	if store.Len() != 2 {
+		t.Fatalf("expected the 40ms key to be evicted, %d keys remain", store.Len())
+	}
+	if fresh, _ := store.MarkSeen(ctx, "2", time.Now().Add(time.Minute)); !fresh {
+		t.Fatal("an evicted key should be accepted again")
+	}
+	if fresh, _ := store.MarkSeen(ctx, "0", time.Now().Add(time.Minute)); fresh {
+		t.Fatal("an unexpired key should still be rejected")
+	}
+}
+
+func TestMemoryReplayStoreConcurrentMarkSeen(t *testing.T) {
+	store := NewMemoryReplayStore()
+	expiresAt := time.Now().Add(time.Minute)
+	var fresh int
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < 64; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if ok, _ := store.MarkSeen(context.Background(), "shared", expiresAt); ok {
+				mutex.Lock()
+				fresh++
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if fresh != 1 {
+		t.Fatalf("expected exactly one fresh use, got %d", fresh)
+	}
+}
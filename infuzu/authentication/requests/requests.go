@@ -1,7 +1,6 @@
 package infuzu
 
 import (
-	"errors"
 	"fmt"
 	base "github.com/infuzu/infuzu-go-sdk/infuzu/authentication/base"
 )
@@ -35,7 +34,7 @@ func (ak AuthenticationKey) String() string {
 
 func (ak AuthenticationKey) PublicKey() (*base.IPublicKey, error) {
 	if ak.PublicKeyB64 == nil {
-		return nil, errors.New("infuzu/authentication/requests.go authentication key has no public key")
+		return nil, fmt.Errorf("%w: authentication key has no public key", base.ErrInvalidPublicKey)
 	}
 	var pk base.IPublicKey
 	err := pk.FromBase64(*ak.PublicKeyB64)
@@ -1,21 +1,83 @@
 package infuzu
 
 import (
-	"encoding/base64"
-	"encoding/json"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
 	"errors"
 	base "github.com/infuzu/infuzu-go-sdk/infuzu/authentication/base"
+	replay "github.com/infuzu/infuzu-go-sdk/infuzu/authentication/replay"
 	constants "github.com/infuzu/infuzu-go-sdk/infuzu/constants"
-	utils "github.com/infuzu/infuzu-go-sdk/infuzu/utils"
 	"os"
+	"strings"
+	"sync"
+	"time"
 )
 
 const SignatureHeaderName = "Infuzu-Signature"
 
+const AllowedTimeDifference = 300
+
+var (
+	ErrPrivateKeyNotFound = errors.New("infuzu/authentication/shortcuts.go private key not found")
+	ErrSignatureReplayed  = errors.New("infuzu/authentication/shortcuts.go signature has already been used")
+	ErrPassphraseNotFound = errors.New("infuzu/authentication/shortcuts.go private key is encrypted but no passphrase was found")
+)
+
+var (
+	ErrMalformedSignature = base.ErrMalformedSignature
+	ErrUnsupportedVersion = base.ErrUnsupportedVersion
+	ErrKeyIDNotFound      = base.ErrKeyIDNotFound
+	ErrSignatureExpired   = base.ErrSignatureExpired
+	ErrKeyIDMismatch      = base.ErrKeyIDMismatch
+	ErrInvalidSignature   = base.ErrInvalidSignature
+	ErrAlgorithmMismatch  = base.ErrAlgorithmMismatch
+)
+
+var (
+	replayStore      replay.ReplayStore
+	replayStoreMutex sync.RWMutex
+)
+
+var (
+	signer      base.Signer
+	signerMutex sync.RWMutex
+)
+
+func SetReplayStore(store replay.ReplayStore) {
+	replayStoreMutex.Lock()
+	defer replayStoreMutex.Unlock()
+	replayStore = store
+}
+
+func GetReplayStore() replay.ReplayStore {
+	replayStoreMutex.RLock()
+	defer replayStoreMutex.RUnlock()
+	return replayStore
+}
+
+func SetSigner(s base.Signer) {
+	signerMutex.Lock()
+	defer signerMutex.Unlock()
+	signer = s
+}
+
+func GetSetSigner() base.Signer {
+	signerMutex.RLock()
+	defer signerMutex.RUnlock()
+	return signer
+}
+
 func GenerateKeyPair() (*base.IKeys, error) {
 	return base.GenerateIKeys()
 }
 
+func GenerateKeyPairWithAlgorithm(algorithm base.Algorithm) (*base.IKeys, error) {
+	return base.GenerateIKeysWithAlgorithm(algorithm)
+}
+
 func GetPrivateKeyStr(privateKeyStr *string) (string, error) {
 	if privateKeyStr != nil {
 		return *privateKeyStr, nil
@@ -31,7 +93,30 @@ func GetPrivateKeyStr(privateKeyStr *string) (string, error) {
 		return envPrivateKey, nil
 	}
 
-	return "", errors.New("infuzu/authentication/shortcuts.go private key not found")
+	return "", ErrPrivateKeyNotFound
+}
+
+func GetPrivateKeyPassphrase() ([]byte, error) {
+	if setPassphrase := constants.GetSetPrivateKeyPassphrase(); len(setPassphrase) > 0 {
+		return setPassphrase, nil
+	}
+
+	if envPassphrase := os.Getenv("INFUZU_SECRET_KEY_PASSPHRASE"); envPassphrase != "" {
+		return []byte(envPassphrase), nil
+	}
+
+	if passphraseFile := os.Getenv("INFUZU_SECRET_KEY_PASSPHRASE_FILE"); passphraseFile != "" {
+		passphrase, err := os.ReadFile(passphraseFile)
+		if err != nil {
+			return nil, err
+		}
+		passphrase = bytes.TrimRight(passphrase, "\r\n")
+		if len(passphrase) > 0 {
+			return passphrase, nil
+		}
+	}
+
+	return nil, ErrPassphraseNotFound
 }
 
 func GetPrivateKey(privateKeyStr *string) (*base.IPrivateKey, error) {
@@ -40,6 +125,15 @@ func GetPrivateKey(privateKeyStr *string) (*base.IPrivateKey, error) {
 		return nil, err
 	}
 
+	if base.IsEncryptedPrivateKey(privateKeyString) {
+		var passphrase []byte
+		passphrase, err = GetPrivateKeyPassphrase()
+		if err != nil {
+			return nil, err
+		}
+		return GetEncryptedPrivateKey(privateKeyString, passphrase)
+	}
+
 	privateKey := &base.IPrivateKey{}
 	err = privateKey.FromBase64(privateKeyString)
 	if err != nil {
@@ -48,6 +142,55 @@ func GetPrivateKey(privateKeyStr *string) (*base.IPrivateKey, error) {
 	return privateKey, nil
 }
 
+// Decrypting runs scrypt, so the most recently decrypted key is kept. The
+// passphrase is remembered only as an HMAC under a per-process random key.
+var decryptedPrivateKey struct {
+	mutex         sync.Mutex
+	macKey        []byte
+	encrypted     string
+	passphraseMAC []byte
+	privateKey    *base.IPrivateKey
+}
+
+func passphraseMAC(passphrase []byte) ([]byte, error) {
+	if decryptedPrivateKey.macKey == nil {
+		macKey := make([]byte, sha256.Size)
+		if _, err := rand.Read(macKey); err != nil {
+			return nil, err
+		}
+		decryptedPrivateKey.macKey = macKey
+	}
+	mac := hmac.New(sha256.New, decryptedPrivateKey.macKey)
+	mac.Write(passphrase)
+	return mac.Sum(nil), nil
+}
+
+func GetEncryptedPrivateKey(encryptedPrivateKeyStr string, passphrase []byte) (*base.IPrivateKey, error) {
+	decryptedPrivateKey.mutex.Lock()
+	defer decryptedPrivateKey.mutex.Unlock()
+
+	digest, err := passphraseMAC(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if decryptedPrivateKey.privateKey != nil &&
+		decryptedPrivateKey.encrypted == encryptedPrivateKeyStr &&
+		hmac.Equal(decryptedPrivateKey.passphraseMAC, digest) {
+		privateKey := *decryptedPrivateKey.privateKey
+		return &privateKey, nil
+	}
+
+	privateKey := &base.IPrivateKey{}
+	if err = privateKey.FromEncryptedBase64(encryptedPrivateKeyStr, passphrase); err != nil {
+		return nil, err
+	}
+	cached := *privateKey
+	decryptedPrivateKey.encrypted = encryptedPrivateKeyStr
+	decryptedPrivateKey.passphraseMAC = digest
+	decryptedPrivateKey.privateKey = &cached
+	return privateKey, nil
+}
+
 func GetPublicKey(publicKeyStr string) (*base.IPublicKey, error) {
 	publicKey := &base.IPublicKey{}
 	err := publicKey.FromBase64(publicKeyStr)
@@ -57,47 +200,144 @@ func GetPublicKey(publicKeyStr string) (*base.IPublicKey, error) {
 	return publicKey, nil
 }
 
-func GenerateMessageSignature(message string, privateKeyStr *string) (string, error) {
+func GetSigner(privateKeyStr *string) (base.Signer, error) {
+	if privateKeyStr == nil {
+		if setSigner := GetSetSigner(); setSigner != nil {
+			return setSigner, nil
+		}
+	}
 	privateKey, err := GetPrivateKey(privateKeyStr)
+	if err != nil {
+		return nil, err
+	}
+	return privateKey, nil
+}
+
+func GenerateMessageSignature(message string, privateKeyStr *string) (string, error) {
+	s, err := GetSigner(privateKeyStr)
+	if err != nil {
+		return "", err
+	}
+
+	return GenerateMessageSignatureWithSigner(message, s)
+}
+
+func GenerateMessageSignatureWithSigner(message string, s base.Signer) (string, error) {
+	return base.SignMessageWithSigner(s, message, "1.2")
+}
+
+func GenerateRequestSignature(
+	request *base.SignableRequest, signedHeaders []string, privateKeyStr *string,
+) (string, error) {
+	s, err := GetSigner(privateKeyStr)
 	if err != nil {
 		return "", err
 	}
 
-	return privateKey.SignMessage(message, "1.2")
+	return GenerateRequestSignatureWithSigner(request, signedHeaders, s)
+}
+
+func GenerateRequestSignatureWithSigner(
+	request *base.SignableRequest, signedHeaders []string, s base.Signer,
+) (string, error) {
+	return base.SignRequestWithSigner(s, request, signedHeaders, "2.0")
 }
 
 func VerifyMessageSignature(message, signature, publicKeyStr string) (bool, error) {
+	return VerifyMessageSignatureContext(context.Background(), message, signature, publicKeyStr)
+}
+
+func VerifyMessageSignatureContext(ctx context.Context, message, signature, publicKeyStr string) (bool, error) {
 	publicKey, err := GetPublicKey(publicKeyStr)
 	if err != nil {
 		return false, err
 	}
 
-	return publicKey.VerifySignature(message, signature, 300)
+	var isValid bool
+	isValid, err = publicKey.VerifySignature(message, signature, AllowedTimeDifference)
+	if err != nil || !isValid {
+		return isValid, err
+	}
+	return checkReplay(ctx, signature)
 }
 
-func GetKeyPairIDFromSignature(signature string) (string, error) {
-	decodedSignature, err := base64.URLEncoding.DecodeString(signature)
+func VerifyRequestSignature(request *base.SignableRequest, signature, publicKeyStr string) (bool, error) {
+	return VerifyRequestSignatureContext(context.Background(), request, signature, publicKeyStr)
+}
+
+func VerifyRequestSignatureContext(
+	ctx context.Context, request *base.SignableRequest, signature, publicKeyStr string,
+) (bool, error) {
+	publicKey, err := GetPublicKey(publicKeyStr)
 	if err != nil {
-		return "", err
+		return false, err
+	}
+
+	var isValid bool
+	isValid, err = publicKey.VerifyRequestSignature(request, signature, AllowedTimeDifference)
+	if err != nil || !isValid {
+		return isValid, err
 	}
+	return checkReplay(ctx, signature)
+}
 
-	var signatureData map[string]interface{}
-	err = json.Unmarshal(decodedSignature, &signatureData)
+type replayCheckedKey struct{}
+
+// ContextWithReplayChecked marks signature as already recorded in the replay
+// store, so verifying it again with the returned context, as chained
+// middlewares do for one request, does not report it as replayed. Only mark
+// signatures that have just been verified.
+func ContextWithReplayChecked(ctx context.Context, signature string) context.Context {
+	replayKey, _, err := base.SignatureReplayIdentity(signature)
 	if err != nil {
-		return "", err
+		return ctx
 	}
-	version := utils.GetSignatureVersion(signature)
-	if version == "1.0" {
-		if sigID, exists := signatureData["id"].(string); exists {
-			return sigID, nil
-		}
-	} else if version == "1.2" {
-		if sigID, exists := signatureData["i"].(string); exists {
-			return sigID, nil
+	return context.WithValue(ctx, replayCheckedKey{}, replayKey)
+}
+
+func checkReplay(ctx context.Context, signature string) (bool, error) {
+	store := GetReplayStore()
+	if store == nil {
+		return true, nil
+	}
+	replayKey, timestamp, err := base.SignatureReplayIdentity(signature)
+	if err != nil {
+		return false, err
+	}
+	if checked, _ := ctx.Value(replayCheckedKey{}).(string); checked == replayKey {
+		return true, nil
+	}
+	windowCloses := time.Unix(timestamp+AllowedTimeDifference+1, 0)
+	var fresh bool
+	fresh, err = store.MarkSeen(ctx, replayKey, windowCloses)
+	if err != nil {
+		return false, err
+	}
+	if !fresh {
+		return false, ErrSignatureReplayed
+	}
+	return true, nil
+}
+
+// SignatureCoversHeader reports whether a request signature binds the named
+// header. It does not verify the signature.
+func SignatureCoversHeader(signature string, headerName string) bool {
+	parsed, err := base.ParseSignature(signature)
+	if err != nil || parsed.Version != "2.0" {
+		return false
+	}
+	for _, signedHeader := range parsed.SignedHeaders {
+		if strings.EqualFold(strings.TrimSpace(signedHeader), headerName) {
+			return true
 		}
-	} else {
-		return "", errors.New("infuzu/authentication/shortcuts.go signature version not supported")
 	}
+	return false
+}
 
-	return "", errors.New("infuzu/authentication/shortcuts.go signature id not found")
+func GetKeyPairIDFromSignature(signature string) (string, error) {
+	parsed, err := base.ParseSignature(signature)
+	if err != nil {
+		return "", err
+	}
+	return parsed.KeyPairID, nil
 }
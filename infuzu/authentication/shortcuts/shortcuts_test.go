@@ -0,0 +1,211 @@
+package infuzu
+
+import (
+	"crypto/elliptic"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	base "github.com/infuzu/infuzu-go-sdk/infuzu/authentication/base"
+	replay "github.com/infuzu/infuzu-go-sdk/infuzu/authentication/replay"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func mustGenerateKeyPair(t *testing.T) (*base.IKeys, string, string) {
+	t.Helper()
+	keys, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateKey, err := keys.PrivateKey.ToBase64()
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey, err := keys.PublicKey.ToBase64()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keys, privateKey, publicKey
+}
+
+func rewriteSignature(t *testing.T, signature string, rewrite func(raw []byte) []byte) string {
+	t.Helper()
+	decoded, err := base64.URLEncoding.DecodeString(signature)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]interface{}
+	if err = json.Unmarshal(decoded, &fields); err != nil {
+		t.Fatal(err)
+	}
+	raw, err := base64.URLEncoding.DecodeString(fields["s"].(string))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields["s"] = base64.URLEncoding.EncodeToString(rewrite(raw))
+	if decoded, err = json.Marshal(fields); err != nil {
+		t.Fatal(err)
+	}
+	return base64.URLEncoding.EncodeToString(decoded)
+}
+
+func malleateSignature(t *testing.T, signature string) string {
+	t.Helper()
+	return rewriteSignature(t, signature, func(raw []byte) []byte {
+		var esig base.EcdsaSignature
+		if _, err := asn1.Unmarshal(raw, &esig); err != nil {
+			t.Fatal(err)
+		}
+		esig.S = new(big.Int).Sub(elliptic.P521().Params().N, esig.S)
+		encoded, err := asn1.Marshal(esig)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return encoded
+	})
+}
+
+func TestReplayedSignaturesAreRejected(t *testing.T) {
+	SetReplayStore(replay.NewMemoryReplayStore())
+	defer SetReplayStore(nil)
+	_, privateKey, publicKey := mustGenerateKeyPair(t)
+
+	signature, err := GenerateMessageSignature("transfer", &privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if valid, err := VerifyMessageSignature("transfer", signature, publicKey); !valid || err != nil {
+		t.Fatalf("first use should verify, got %v, %v", valid, err)
+	}
+	if _, err = VerifyMessageSignature("transfer", signature, publicKey); !errors.Is(err, ErrSignatureReplayed) {
+		t.Fatalf("expected replay to be rejected, got %v", err)
+	}
+	if _, err = VerifyMessageSignature("transfer", malleateSignature(t, signature), publicKey); !errors.Is(err, ErrSignatureReplayed) {
+		t.Fatalf("expected malleated replay to be rejected, got %v", err)
+	}
+	padded := rewriteSignature(t, signature, func(raw []byte) []byte { return append(raw, 0) })
+	if _, err = VerifyMessageSignature("transfer", padded, publicKey); !errors.Is(err, ErrMalformedSignature) {
+		t.Fatalf("expected padded replay to be rejected, got %v", err)
+	}
+}
+
+func mustEncryptPrivateKey(t *testing.T, keys *base.IKeys, passphrase string) string {
+	t.Helper()
+	encrypted, err := keys.PrivateKey.ToEncryptedBase64WithParameters(
+		[]byte(passphrase), base.ScryptParameters{N: 1 << 10, R: 8, P: 1},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return encrypted
+}
+
+func TestGetPrivateKeyDecryptsWithEnvironmentPassphrase(t *testing.T) {
+	keys, _, _ := mustGenerateKeyPair(t)
+	t.Setenv("INFUZU_SECRET_KEY", mustEncryptPrivateKey(t, keys, "correct horse"))
+	t.Setenv("INFUZU_SECRET_KEY_PASSPHRASE", "")
+	t.Setenv("INFUZU_SECRET_KEY_PASSPHRASE_FILE", "")
+
+	if _, err := GetPrivateKey(nil); !errors.Is(err, ErrPassphraseNotFound) {
+		t.Fatalf("expected ErrPassphraseNotFound, got %v", err)
+	}
+
+	t.Setenv("INFUZU_SECRET_KEY_PASSPHRASE", "correct horse")
+	for i := 0; i < 2; i++ {
+		privateKey, err := GetPrivateKey(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if privateKey.KeyPairID != keys.ID {
+			t.Fatalf("decrypted the wrong key %q", privateKey.KeyPairID)
+		}
+	}
+
+	t.Setenv("INFUZU_SECRET_KEY_PASSPHRASE", "wrong horse")
+	if _, err := GetPrivateKey(nil); !errors.Is(err, base.ErrIncorrectPassphrase) {
+		t.Fatalf("a cached key was returned for the wrong passphrase: %v", err)
+	}
+}
+
+func TestGetPrivateKeyReadsPassphraseFile(t *testing.T) {
+	keys, _, _ := mustGenerateKeyPair(t)
+	passphraseFile := filepath.Join(t.TempDir(), "passphrase")
+	if err := os.WriteFile(passphraseFile, []byte("battery staple\r\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("INFUZU_SECRET_KEY", mustEncryptPrivateKey(t, keys, "battery staple"))
+	t.Setenv("INFUZU_SECRET_KEY_PASSPHRASE", "")
+	t.Setenv("INFUZU_SECRET_KEY_PASSPHRASE_FILE", passphraseFile)
+
+	privateKey, err := GetPrivateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if privateKey.KeyPairID != keys.ID {
+		t.Fatalf("decrypted the wrong key %q", privateKey.KeyPairID)
+	}
+
+	t.Setenv("INFUZU_SECRET_KEY_PASSPHRASE_FILE", filepath.Join(t.TempDir(), "missing"))
+	if _, err = GetPrivateKey(nil); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected a missing passphrase file to be reported, got %v", err)
+	}
+}
+
+func TestEncryptedKeyCacheHoldsTheLatestKey(t *testing.T) {
+	first, _, _ := mustGenerateKeyPair(t)
+	second, _, _ := mustGenerateKeyPair(t)
+	encryptedFirst := mustEncryptPrivateKey(t, first, "passphrase")
+	encryptedSecond := mustEncryptPrivateKey(t, second, "passphrase")
+
+	for _, testCase := range []struct {
+		encrypted string
+		expected  string
+	}{{encryptedFirst, first.ID}, {encryptedSecond, second.ID}, {encryptedFirst, first.ID}} {
+		privateKey, err := GetEncryptedPrivateKey(testCase.encrypted, []byte("passphrase"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if privateKey.KeyPairID != testCase.expected {
+			t.Fatalf("expected key %q, got %q", testCase.expected, privateKey.KeyPairID)
+		}
+	}
+	if decryptedPrivateKey.encrypted != encryptedFirst {
+		t.Fatal("the cache should hold only the most recently decrypted key")
+	}
+}
+
+func TestSetSignerIsUsedByDefault(t *testing.T) {
+	keys, _, publicKey := mustGenerateKeyPair(t)
+	SetSigner(keys.PrivateKey)
+	defer SetSigner(nil)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < cap(errs); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			SetSigner(keys.PrivateKey)
+			signature, err := GenerateMessageSignature("message", nil)
+			if err == nil {
+				var valid bool
+				valid, err = VerifyMessageSignature("message", signature, publicKey)
+				if err == nil && !valid {
+					err = errors.New("signature did not verify")
+				}
+			}
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package infuzu
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	base "github.com/infuzu/infuzu-go-sdk/infuzu/authentication/base"
+	"sync"
+)
+
+// Signer holds an Ed25519 key derived from its key pair ID, so the same ID
+// always yields the same key and the same signature for the same digest.
+type Signer struct {
+	privateKey *base.IPrivateKey
+	Err        error
+	digests    [][]byte
+	mutex      sync.Mutex
+}
+
+func NewSigner(keyPairID string) *Signer {
+	seed := sha256.Sum256([]byte("infuzu signertest " + keyPairID))
+	return &Signer{
+		privateKey: &base.IPrivateKey{
+			Ed25519PrivateKey: ed25519.NewKeyFromSeed(seed[:]),
+			KeyPairID:         keyPairID,
+		},
+	}
+}
+
+func (s *Signer) KeyID() string {
+	return s.privateKey.KeyPairID
+}
+
+func (s *Signer) Algorithm() base.Algorithm {
+	return base.AlgorithmEd25519
+}
+
+func (s *Signer) Sign(digest []byte) ([]byte, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.digests = append(s.digests, append([]byte(nil), digest...))
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	return s.privateKey.Sign(digest)
+}
+
+func (s *Signer) PublicKey() *base.IPublicKey {
+	return s.privateKey.PublicKey()
+}
+
+func (s *Signer) PublicKeyBase64() string {
+	encoded, err := s.PublicKey().ToBase64()
+	if err != nil {
+		panic(err)
+	}
+	return encoded
+}
+
+func (s *Signer) Digests() [][]byte {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([][]byte(nil), s.digests...)
+}
+
+var _ base.AlgorithmSigner = (*Signer)(nil)
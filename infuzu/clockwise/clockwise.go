@@ -0,0 +1,92 @@
+package infuzu
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	constants "github.com/infuzu/infuzu-go-sdk/infuzu/constants"
+	requests "github.com/infuzu/infuzu-go-sdk/infuzu/requests"
+	utils "github.com/infuzu/infuzu-go-sdk/infuzu/utils"
+	"net/http"
+	"time"
+)
+
+var ErrNoAssignment = errors.New("infuzu/clockwise/clockwise.go no assignment available")
+
+type Assignment struct {
+	ID           string          `json:"id"`
+	RuleID       string          `json:"rule_id"`
+	TaskType     string          `json:"task_type"`
+	Payload      json.RawMessage `json:"payload,omitempty"`
+	ScheduledFor *time.Time      `json:"scheduled_for,omitempty"`
+}
+
+type TaskCompletion struct {
+	AssignmentID string    `json:"assignment_id"`
+	RuleID       string    `json:"rule_id"`
+	Success      bool      `json:"success"`
+	StartTime    time.Time `json:"start_datetime"`
+	EndTime      time.Time `json:"end_datetime"`
+	Logs         string    `json:"logs,omitempty"`
+}
+
+type Client struct {
+	Session *requests.SignatureSession
+	BaseURL string
+}
+
+func NewClient(session *requests.SignatureSession) *Client {
+	if session == nil {
+		session = requests.SignedClient
+	}
+	return &Client{Session: session}
+}
+
+var DefaultClient = NewClient(nil)
+
+func (c *Client) baseURL() string {
+	if c.BaseURL != "" {
+		return c.BaseURL
+	}
+	return constants.ClockwiseBaseUrl()
+}
+
+func (c *Client) RetrieveAssignment(ctx context.Context) (*Assignment, error) {
+	var assignment Assignment
+	assignmentURL, err := utils.BuildURL(c.baseURL(), constants.ClockwiseRetrieveAssignmentEndpoint(), nil)
+	if err != nil {
+		return nil, err
+	}
+	var statusCode int
+	statusCode, err = c.Session.RequestJSON(ctx, "GET", assignmentURL, nil, &assignment)
+	if statusCode == http.StatusNoContent {
+		return nil, ErrNoAssignment
+	}
+	if err != nil {
+		return nil, err
+	}
+	if assignment.ID == "" && assignment.RuleID == "" {
+		return nil, ErrNoAssignment
+	}
+	return &assignment, nil
+}
+
+func (c *Client) CompleteAssignment(ctx context.Context, completion *TaskCompletion) error {
+	if completion == nil {
+		return errors.New("infuzu/clockwise/clockwise.go task completion cannot be nil")
+	}
+	completeURL, err := utils.BuildURL(c.baseURL(), constants.ClockwiseAssignmentCompleteEndpoint(), nil)
+	if err != nil {
+		return err
+	}
+	_, err = c.Session.RequestJSON(ctx, "POST", completeURL, completion, nil)
+	return err
+}
+
+func RetrieveAssignment(ctx context.Context) (*Assignment, error) {
+	return DefaultClient.RetrieveAssignment(ctx)
+}
+
+func CompleteAssignment(ctx context.Context, completion *TaskCompletion) error {
+	return DefaultClient.CompleteAssignment(ctx, completion)
+}
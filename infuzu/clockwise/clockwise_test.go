@@ -0,0 +1,95 @@
+package infuzu_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	base "github.com/infuzu/infuzu-go-sdk/infuzu/authentication/base"
+	clockwise "github.com/infuzu/infuzu-go-sdk/infuzu/clockwise"
+	clockwisetest "github.com/infuzu/infuzu-go-sdk/infuzu/clockwise/clockwisetest"
+	requests "github.com/infuzu/infuzu-go-sdk/infuzu/requests"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func newServer(t *testing.T) *clockwisetest.Server {
+	t.Helper()
+	server := clockwisetest.NewServer()
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRetrieveAndCompleteAssignment(t *testing.T) {
+	server := newServer(t)
+	client := server.Client(nil)
+	ctx := context.Background()
+	server.Enqueue(clockwise.Assignment{
+		ID: "assignment-1", RuleID: "rule-1", TaskType: "email", Payload: json.RawMessage(`{"to":"a@b.c"}`),
+	})
+
+	assignment, err := client.RetrieveAssignment(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assignment.ID != "assignment-1" || assignment.TaskType != "email" || string(assignment.Payload) != `{"to":"a@b.c"}` {
+		t.Fatalf("unexpected assignment %+v", assignment)
+	}
+	if _, err = client.RetrieveAssignment(ctx); !errors.Is(err, clockwise.ErrNoAssignment) {
+		t.Fatalf("expected an empty queue, got %v", err)
+	}
+
+	startTime := time.Now().UTC().Truncate(time.Second)
+	err = client.CompleteAssignment(ctx, &clockwise.TaskCompletion{
+		AssignmentID: assignment.ID,
+		RuleID:       assignment.RuleID,
+		Success:      true,
+		StartTime:    startTime,
+		EndTime:      startTime.Add(time.Second),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	completions := server.Completions()
+	if len(completions) != 1 || completions[0].AssignmentID != "assignment-1" || !completions[0].Success {
+		t.Fatalf("unexpected completions %+v", completions)
+	}
+	if server.UnsignedRequests() != 0 {
+		t.Fatalf("%d requests were rejected", server.UnsignedRequests())
+	}
+}
+
+func TestRetrieveAssignmentReportsMissingEndpoint(t *testing.T) {
+	server := newServer(t)
+	client := server.Client(nil)
+	client.BaseURL = server.URL + "/missing/"
+	_, err := client.RetrieveAssignment(context.Background())
+	if errors.Is(err, clockwise.ErrNoAssignment) {
+		t.Fatal("a missing endpoint was reported as an empty queue")
+	}
+	if apiError, ok := requests.AsAPIError(err); !ok || apiError.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected a 404 API error, got %v", err)
+	}
+}
+
+func TestServerRejectsForeignSignatures(t *testing.T) {
+	server := newServer(t)
+	otherKeys, err := base.GenerateIKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherPrivateKey, err := otherKeys.PrivateKey.ToBase64()
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := server.Client(requests.NewSignatureSession(&otherPrivateKey))
+	server.Enqueue(clockwise.Assignment{ID: "assignment-1", TaskType: "email"})
+
+	_, err = client.RetrieveAssignment(context.Background())
+	if apiError, ok := requests.AsAPIError(err); !ok || apiError.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected a 403 API error, got %v", err)
+	}
+	if server.UnsignedRequests() != 1 || server.Pending() != 1 {
+		t.Fatal("a request signed with the wrong key was served")
+	}
+}
@@ -0,0 +1,307 @@
+package infuzu
+
+import (
+	"bytes"
+	"encoding/json"
+	base "github.com/infuzu/infuzu-go-sdk/infuzu/authentication/base"
+	shortcuts "github.com/infuzu/infuzu-go-sdk/infuzu/authentication/shortcuts"
+	clockwise "github.com/infuzu/infuzu-go-sdk/infuzu/clockwise"
+	constants "github.com/infuzu/infuzu-go-sdk/infuzu/constants"
+	requests "github.com/infuzu/infuzu-go-sdk/infuzu/requests"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Server struct {
+	*httptest.Server
+	LogPageSize int
+	Keys        *base.IKeys
+	privateKey  string
+	publicKey   string
+	assignments []clockwise.Assignment
+	completions []clockwise.TaskCompletion
+	rules       map[string]clockwise.Rule
+	ruleLogs    map[string][]clockwise.RuleLogEntry
+	nextRuleID  int
+	unsigned    int
+	failures    int
+	failStatus  int
+	mutex       sync.Mutex
+}
+
+func NewServer() *Server {
+	keys, err := base.GenerateIKeys()
+	if err != nil {
+		panic(err)
+	}
+	return NewServerWithKeys(keys)
+}
+
+func NewServerWithKeys(keys *base.IKeys) *Server {
+	privateKey, err := keys.PrivateKey.ToBase64()
+	if err != nil {
+		panic(err)
+	}
+	var publicKey string
+	publicKey, err = keys.PublicKey.ToBase64()
+	if err != nil {
+		panic(err)
+	}
+	s := &Server{
+		LogPageSize: 10,
+		Keys:        keys,
+		privateKey:  privateKey,
+		publicKey:   publicKey,
+		rules:       make(map[string]clockwise.Rule),
+		ruleLogs:    make(map[string][]clockwise.RuleLogEntry),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/"+strings.TrimPrefix(constants.ClockwiseRetrieveAssignmentEndpoint(), "/"), s.handleAssignment)
+	mux.HandleFunc("/"+strings.TrimPrefix(constants.ClockwiseAssignmentCompleteEndpoint(), "/"), s.handleComplete)
+	mux.HandleFunc("/"+strings.TrimPrefix(constants.ClockwiseCreateRuleEndpoint(), "/"), s.handleCreateRule)
+	mux.HandleFunc(endpointPrefix(constants.ClockwiseDeleteRuleEndpoint()), s.handleDeleteRule)
+	mux.HandleFunc(endpointPrefix(constants.ClockwiseRuleLogsEndpoint()), s.handleRuleLogs)
+	s.Server = httptest.NewServer(s.requireSignature(mux))
+	return s
+}
+
+func (s *Server) Session() *requests.SignatureSession {
+	return requests.NewSignatureSession(&s.privateKey)
+}
+
+func (s *Server) Client(session *requests.SignatureSession) *clockwise.Client {
+	if session == nil {
+		session = s.Session()
+	}
+	client := clockwise.NewClient(session)
+	client.BaseURL = s.URL + "/"
+	return client
+}
+
+func (s *Server) Enqueue(assignments ...clockwise.Assignment) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.assignments = append(s.assignments, assignments...)
+}
+
+func (s *Server) Completions() []clockwise.TaskCompletion {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]clockwise.TaskCompletion(nil), s.completions...)
+}
+
+func (s *Server) FailNext(count int, statusCode int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.failures = count
+	s.failStatus = statusCode
+}
+
+func (s *Server) Pending() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.assignments)
+}
+
+// UnsignedRequests counts requests rejected for a missing or invalid signature.
+func (s *Server) UnsignedRequests() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.unsigned
+}
+
+func (s *Server) Rules() []clockwise.Rule {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	rules := make([]clockwise.Rule, 0, len(s.rules))
+	for _, rule := range s.rules {
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+func (s *Server) AddRuleLogs(ruleID string, entries ...clockwise.RuleLogEntry) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, entry := range entries {
+		entry.RuleID = ruleID
+		s.ruleLogs[ruleID] = append(s.ruleLogs[ruleID], entry)
+	}
+}
+
+func (s *Server) requireSignature(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		valid, err := shortcuts.VerifyRequestSignature(
+			base.NewSignableRequest(r, body), r.Header.Get(shortcuts.SignatureHeaderName), s.publicKey,
+		)
+		if err != nil || !valid {
+			s.mutex.Lock()
+			s.unsigned++
+			s.mutex.Unlock()
+			writeJSON(w, http.StatusForbidden, map[string]string{"error": "Access Denied - Signature is invalid"})
+			return
+		}
+		s.mutex.Lock()
+		failing := s.failures > 0
+		if failing {
+			s.failures--
+		}
+		failStatus := s.failStatus
+		s.mutex.Unlock()
+		if failing {
+			writeJSON(w, failStatus, map[string]string{"error": "injected failure"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) handleAssignment(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	s.mutex.Lock()
+	if len(s.assignments) == 0 {
+		s.mutex.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	assignment := s.assignments[0]
+	s.assignments = s.assignments[1:]
+	s.mutex.Unlock()
+	writeJSON(w, http.StatusOK, assignment)
+}
+
+func (s *Server) handleComplete(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var completion clockwise.TaskCompletion
+	if err := json.NewDecoder(r.Body).Decode(&completion); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	s.mutex.Lock()
+	s.completions = append(s.completions, completion)
+	s.mutex.Unlock()
+	writeJSON(w, http.StatusOK, map[string]bool{"success": true})
+}
+
+func (s *Server) handleCreateRule(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var rule clockwise.Rule
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	s.mutex.Lock()
+	s.nextRuleID++
+	rule.ID = "rule-" + strconv.Itoa(s.nextRuleID)
+	createdAt := time.Now().UTC()
+	rule.CreatedAt = &createdAt
+	s.rules[rule.ID] = rule
+	s.mutex.Unlock()
+	writeJSON(w, http.StatusCreated, rule)
+}
+
+func (s *Server) handleDeleteRule(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	ruleID := pathParameter(r, constants.ClockwiseDeleteRuleEndpoint())
+	s.mutex.Lock()
+	_, exists := s.rules[ruleID]
+	delete(s.rules, ruleID)
+	s.mutex.Unlock()
+	if !exists {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "Rule not found"})
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleRuleLogs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	ruleID := pathParameter(r, constants.ClockwiseRuleLogsEndpoint())
+	page := 1
+	if requestedPage, err := strconv.Atoi(r.URL.Query().Get("page")); err == nil && requestedPage > 0 {
+		page = requestedPage
+	}
+	s.mutex.Lock()
+	entries := s.ruleLogs[ruleID]
+	pageSize := s.LogPageSize
+	s.mutex.Unlock()
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	start := (page - 1) * pageSize
+	if start > len(entries) {
+		start = len(entries)
+	}
+	end := start + pageSize
+	if end > len(entries) {
+		end = len(entries)
+	}
+	logPage := clockwise.RuleLogPage{
+		Count:   len(entries),
+		Results: append([]clockwise.RuleLogEntry{}, entries[start:end]...),
+	}
+	pageURL := *r.URL
+	pageURL.Scheme = "http"
+	pageURL.Host = r.Host
+	if end < len(entries) {
+		pageURL.RawQuery = "page=" + strconv.Itoa(page+1)
+		next := pageURL.String()
+		logPage.Next = &next
+	}
+	if page > 1 {
+		pageURL.RawQuery = "page=" + strconv.Itoa(page-1)
+		previous := pageURL.String()
+		logPage.Previous = &previous
+	}
+	writeJSON(w, http.StatusOK, logPage)
+}
+
+func endpointPrefix(endpoint string) string {
+	if index := strings.Index(endpoint, "<"); index >= 0 {
+		endpoint = endpoint[:index]
+	}
+	return "/" + strings.TrimPrefix(endpoint, "/")
+}
+
+func pathParameter(r *http.Request, endpoint string) string {
+	parameter := strings.TrimPrefix(r.URL.EscapedPath(), endpointPrefix(endpoint))
+	parameter = strings.TrimSuffix(parameter, "/")
+	if unescaped, err := url.PathUnescape(parameter); err == nil {
+		return unescaped
+	}
+	return parameter
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(value)
+}
@@ -0,0 +1,206 @@
+package infuzu
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	constants "github.com/infuzu/infuzu-go-sdk/infuzu/constants"
+	utils "github.com/infuzu/infuzu-go-sdk/infuzu/utils"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type Schedule struct {
+	Cron            string     `json:"cron,omitempty"`
+	IntervalSeconds int64      `json:"interval_seconds,omitempty"`
+	StartTime       *time.Time `json:"start_datetime,omitempty"`
+	EndTime         *time.Time `json:"end_datetime,omitempty"`
+	Timezone        string     `json:"timezone,omitempty"`
+}
+
+type Target struct {
+	TaskType string `json:"task_type,omitempty"`
+	URL      string `json:"url,omitempty"`
+	Method   string `json:"method,omitempty"`
+}
+
+type Rule struct {
+	ID        string          `json:"id,omitempty"`
+	Name      string          `json:"name"`
+	Schedule  Schedule        `json:"schedule"`
+	Target    Target          `json:"target"`
+	Payload   json.RawMessage `json:"payload,omitempty"`
+	Enabled   *bool           `json:"enabled,omitempty"`
+	CreatedAt *time.Time      `json:"created_at,omitempty"`
+}
+
+type RuleLogEntry struct {
+	ID           string     `json:"id"`
+	RuleID       string     `json:"rule_id"`
+	AssignmentID string     `json:"assignment_id,omitempty"`
+	Success      bool       `json:"success"`
+	StartTime    *time.Time `json:"start_datetime,omitempty"`
+	EndTime      *time.Time `json:"end_datetime,omitempty"`
+	Logs         string     `json:"logs,omitempty"`
+}
+
+type RuleLogPage struct {
+	Count    int            `json:"count"`
+	Next     *string        `json:"next"`
+	Previous *string        `json:"previous"`
+	Results  []RuleLogEntry `json:"results"`
+}
+
+func (c *Client) CreateRule(ctx context.Context, rule *Rule) (*Rule, error) {
+	if rule == nil {
+		return nil, errors.New("infuzu/clockwise/rules.go rule cannot be nil")
+	}
+	createURL, err := utils.BuildURL(c.baseURL(), constants.ClockwiseCreateRuleEndpoint(), nil)
+	if err != nil {
+		return nil, err
+	}
+	var created Rule
+	_, err = c.Session.RequestJSON(ctx, "POST", createURL, rule, &created)
+	if err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+func (c *Client) DeleteRule(ctx context.Context, ruleID string) error {
+	deleteURL, err := utils.BuildURL(
+		c.baseURL(), constants.ClockwiseDeleteRuleEndpoint(), map[string]interface{}{"rule_id": ruleID},
+	)
+	if err != nil {
+		return err
+	}
+	_, err = c.Session.RequestJSON(ctx, "DELETE", deleteURL, nil, nil)
+	return err
+}
+
+func (c *Client) RuleLogs(ctx context.Context, ruleID string, page int) (*RuleLogPage, error) {
+	pageURL, err := utils.BuildURL(
+		c.baseURL(), constants.ClockwiseRuleLogsEndpoint(), map[string]interface{}{"rule_id": ruleID},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if page > 1 {
+		pageURL += "?page=" + strconv.Itoa(page)
+	}
+	return c.ruleLogPage(ctx, pageURL)
+}
+
+func (c *Client) ruleLogPage(ctx context.Context, pageURL string) (*RuleLogPage, error) {
+	var logPage RuleLogPage
+	_, err := c.Session.RequestJSON(ctx, "GET", pageURL, nil, &logPage)
+	if err != nil {
+		return nil, err
+	}
+	return &logPage, nil
+}
+
+type RuleLogIterator struct {
+	client  *Client
+	ruleID  string
+	nextURL string
+	page    *RuleLogPage
+	index   int
+	started bool
+	err     error
+}
+
+func (c *Client) IterateRuleLogs(ruleID string) *RuleLogIterator {
+	return &RuleLogIterator{client: c, ruleID: ruleID}
+}
+
+func (it *RuleLogIterator) Next(ctx context.Context) bool {
+	if it.err != nil {
+		return false
+	}
+	for it.page == nil || it.index+1 >= len(it.page.Results) {
+		if it.started && it.nextURL == "" {
+			it.page = nil
+			return false
+		}
+		if !it.fetch(ctx) {
+			return false
+		}
+	}
+	it.index++
+	return true
+}
+
+func (it *RuleLogIterator) fetch(ctx context.Context) bool {
+	var page *RuleLogPage
+	if !it.started {
+		it.started = true
+		page, it.err = it.client.RuleLogs(ctx, it.ruleID, 1)
+	} else {
+		var pageURL string
+		if pageURL, it.err = it.client.pageURL(it.nextURL); it.err != nil {
+			return false
+		}
+		page, it.err = it.client.ruleLogPage(ctx, pageURL)
+	}
+	if it.err != nil {
+		return false
+	}
+	it.page = page
+	it.index = -1
+	it.nextURL = ""
+	if page.Next != nil {
+		it.nextURL = *page.Next
+	}
+	return true
+}
+
+func (it *RuleLogIterator) Entry() *RuleLogEntry {
+	if it.page == nil || it.index < 0 || it.index >= len(it.page.Results) {
+		return nil
+	}
+	return &it.page.Results[it.index]
+}
+
+func (it *RuleLogIterator) Err() error {
+	return it.err
+}
+
+// pageURL rebases a page link onto the configured base URL. Servers behind a
+// TLS-terminating proxy often link to http:// pages, so only the host is
+// checked and the scheme always comes from the base URL.
+func (c *Client) pageURL(link string) (string, error) {
+	base, err := url.Parse(c.baseURL())
+	if err != nil {
+		return "", err
+	}
+	var next *url.URL
+	next, err = url.Parse(link)
+	if err != nil {
+		return "", err
+	}
+	if next.Host != "" && next.Host != base.Host {
+		return "", fmt.Errorf("infuzu/clockwise/rules.go refusing to follow page link to %s", next.Host)
+	}
+	rebased := *base
+	rebased.Path, rebased.RawPath, rebased.RawQuery = next.Path, next.RawPath, next.RawQuery
+	return rebased.String(), nil
+}
+
+func CreateRule(ctx context.Context, rule *Rule) (*Rule, error) {
+	return DefaultClient.CreateRule(ctx, rule)
+}
+
+func DeleteRule(ctx context.Context, ruleID string) error {
+	return DefaultClient.DeleteRule(ctx, ruleID)
+}
+
+func RuleLogs(ctx context.Context, ruleID string, page int) (*RuleLogPage, error) {
+	return DefaultClient.RuleLogs(ctx, ruleID, page)
+}
+
+func IterateRuleLogs(ruleID string) *RuleLogIterator {
+	return DefaultClient.IterateRuleLogs(ruleID)
+}
@@ -0,0 +1,155 @@
+package infuzu_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	clockwise "github.com/infuzu/infuzu-go-sdk/infuzu/clockwise"
+	requests "github.com/infuzu/infuzu-go-sdk/infuzu/requests"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCreateAndDeleteRule(t *testing.T) {
+	server := newServer(t)
+	client := server.Client(nil)
+	ctx := context.Background()
+
+	created, err := client.CreateRule(ctx, &clockwise.Rule{
+		Name:     "nightly",
+		Schedule: clockwise.Schedule{Cron: "0 3 * * *"},
+		Target:   clockwise.Target{TaskType: "report"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.ID == "" || created.CreatedAt == nil || len(server.Rules()) != 1 {
+		t.Fatalf("unexpected created rule %+v", created)
+	}
+	if err = client.DeleteRule(ctx, created.ID); err != nil {
+		t.Fatal(err)
+	}
+	if len(server.Rules()) != 0 {
+		t.Fatal("the rule was not deleted")
+	}
+	if apiError, ok := requests.AsAPIError(client.DeleteRule(ctx, created.ID)); !ok || apiError.StatusCode != http.StatusNotFound {
+		t.Fatal("deleting a missing rule should report a 404")
+	}
+	if _, err = client.CreateRule(ctx, nil); err == nil {
+		t.Fatal("a nil rule was sent")
+	}
+}
+
+func TestIterateRuleLogsFollowsPages(t *testing.T) {
+	server := newServer(t)
+	server.LogPageSize = 2
+	for i := 1; i <= 5; i++ {
+		server.AddRuleLogs("rule-1", clockwise.RuleLogEntry{ID: fmt.Sprint("log-", i), Success: true})
+	}
+	client := server.Client(nil)
+	ctx := context.Background()
+
+	page, err := client.RuleLogs(ctx, "rule-1", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Count != 5 || len(page.Results) != 2 || page.Next == nil || page.Previous == nil {
+		t.Fatalf("unexpected page %+v", page)
+	}
+
+	var seen []string
+	iterator := client.IterateRuleLogs("rule-1")
+	for iterator.Next(ctx) {
+		seen = append(seen, iterator.Entry().ID)
+	}
+	if err = iterator.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(seen, ","); got != "log-1,log-2,log-3,log-4,log-5" {
+		t.Fatalf("unexpected entries %s", got)
+	}
+	if iterator.Next(ctx) || iterator.Entry() != nil {
+		t.Fatal("an exhausted iterator should stay exhausted")
+	}
+
+	empty := client.IterateRuleLogs("rule-2")
+	if empty.Next(ctx) || empty.Err() != nil {
+		t.Fatalf("expected no entries for an empty log, got %v", empty.Err())
+	}
+}
+
+func TestIterateRuleLogsStopsOnErrors(t *testing.T) {
+	server := newServer(t)
+	server.LogPageSize = 1
+	server.AddRuleLogs("rule-1", clockwise.RuleLogEntry{ID: "log-1"}, clockwise.RuleLogEntry{ID: "log-2"})
+	client := server.Client(nil)
+	ctx := context.Background()
+
+	iterator := client.IterateRuleLogs("rule-1")
+	if !iterator.Next(ctx) || iterator.Entry().ID != "log-1" {
+		t.Fatal("expected the first entry")
+	}
+	server.FailNext(1, http.StatusBadGateway)
+	if iterator.Next(ctx) {
+		t.Fatal("the iterator continued past a failed page")
+	}
+	if apiError, ok := requests.AsAPIError(iterator.Err()); !ok || apiError.StatusCode != http.StatusBadGateway {
+		t.Fatalf("expected the page error, got %v", iterator.Err())
+	}
+}
+
+func TestIterateRuleLogsRefusesForeignPageLinks(t *testing.T) {
+	server := newServer(t)
+	foreign := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the iterator followed a link to another host")
+	}))
+	defer foreign.Close()
+	next := foreign.URL + "/logs/?page=2"
+	relay := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(clockwise.RuleLogPage{
+			Count: 2, Next: &next, Results: []clockwise.RuleLogEntry{{ID: "log-1"}},
+		})
+	}))
+	defer relay.Close()
+	client := server.Client(nil)
+	client.BaseURL = relay.URL + "/"
+
+	iterator := client.IterateRuleLogs("rule-1")
+	if !iterator.Next(context.Background()) {
+		t.Fatalf("expected the first entry, got %v", iterator.Err())
+	}
+	if iterator.Next(context.Background()) || iterator.Err() == nil {
+		t.Fatal("expected the foreign page link to be refused")
+	}
+}
+
+func TestIterateRuleLogsFollowsProxiedPageLinks(t *testing.T) {
+	server := newServer(t)
+	relay := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			_ = json.NewEncoder(w).Encode(clockwise.RuleLogPage{Count: 2, Results: []clockwise.RuleLogEntry{{ID: "log-2"}}})
+			return
+		}
+		next := "https://" + r.Host + "/logs/?page=2"
+		_ = json.NewEncoder(w).Encode(clockwise.RuleLogPage{
+			Count: 2, Next: &next, Results: []clockwise.RuleLogEntry{{ID: "log-1"}},
+		})
+	}))
+	defer relay.Close()
+	client := server.Client(nil)
+	client.BaseURL = relay.URL + "/"
+
+	var seen []string
+	iterator := client.IterateRuleLogs("rule-1")
+	for iterator.Next(context.Background()) {
+		seen = append(seen, iterator.Entry().ID)
+	}
+	if err := iterator.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(seen, ","); got != "log-1,log-2" {
+		t.Fatalf("unexpected entries %s", got)
+	}
+}
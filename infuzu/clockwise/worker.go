@@ -0,0 +1,221 @@
+package infuzu
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+var ErrNoHandler = errors.New("infuzu/clockwise/worker.go no handler registered for task type")
+
+type TaskPanicError struct {
+	AssignmentID string
+	Value        interface{}
+	Stack        []byte
+}
+
+func (e *TaskPanicError) Error() string {
+	return fmt.Sprintf("infuzu/clockwise/worker.go task %s panicked: %v", e.AssignmentID, e.Value)
+}
+
+type Handler func(ctx context.Context, assignment *Assignment) error
+
+type WorkerHooks struct {
+	OnPoll          func(assignment *Assignment, err error)
+	OnTaskStart     func(assignment *Assignment)
+	OnTaskFinish    func(assignment *Assignment, duration time.Duration, err error)
+	OnCompleteError func(assignment *Assignment, err error)
+	OnBackoff       func(delay time.Duration)
+}
+
+type Worker struct {
+	Client          *Client
+	Concurrency     int
+	PollInterval    time.Duration
+	MaxPollInterval time.Duration
+	MinBackoff      time.Duration
+	MaxBackoff      time.Duration
+	ReportFailures  bool
+	Hooks           WorkerHooks
+	handlers        map[string]Handler
+	mutex           sync.RWMutex
+}
+
+func NewWorker(client *Client) *Worker {
+	if client == nil {
+		client = DefaultClient
+	}
+	return &Worker{
+		Client:          client,
+		Concurrency:     1,
+		PollInterval:    time.Second,
+		MaxPollInterval: 30 * time.Second,
+		MinBackoff:      time.Second,
+		MaxBackoff:      30 * time.Second,
+		handlers:        make(map[string]Handler),
+	}
+}
+
+func (w *Worker) Handle(taskType string, handler Handler) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if handler == nil {
+		delete(w.handlers, taskType)
+		return
+	}
+	w.handlers[taskType] = handler
+}
+
+func (w *Worker) handler(taskType string) (Handler, bool) {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+	handler, exists := w.handlers[taskType]
+	return handler, exists
+}
+
+func (w *Worker) Run(ctx context.Context) error {
+	if w.Client == nil {
+		return errors.New("infuzu/clockwise/worker.go worker has no client")
+	}
+	concurrency := w.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.loop(ctx)
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// loop waits longer after each consecutive empty poll, up to MaxPollInterval,
+// and backs off after poll errors and after failed tasks that are not reported,
+// since the service redelivers those.
+func (w *Worker) loop(ctx context.Context) {
+	idlePolls, failures := 0, 0
+	for ctx.Err() == nil {
+		assignment, err := w.Client.RetrieveAssignment(ctx)
+		if w.Hooks.OnPoll != nil {
+			w.Hooks.OnPoll(assignment, err)
+		}
+		if errors.Is(err, ErrNoAssignment) {
+			failures = 0
+			idlePolls++
+			w.wait(ctx, w.pollInterval(idlePolls))
+			continue
+		}
+		idlePolls = 0
+		if err == nil && w.process(ctx, assignment) {
+			failures = 0
+			continue
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		failures++
+		delay := w.backoff(failures)
+		if w.Hooks.OnBackoff != nil {
+			w.Hooks.OnBackoff(delay)
+		}
+		w.wait(ctx, delay)
+	}
+}
+
+func (w *Worker) process(ctx context.Context, assignment *Assignment) bool {
+	if w.Hooks.OnTaskStart != nil {
+		w.Hooks.OnTaskStart(assignment)
+	}
+	startTime := time.Now()
+	err := w.execute(ctx, assignment)
+	endTime := time.Now()
+	if w.Hooks.OnTaskFinish != nil {
+		w.Hooks.OnTaskFinish(assignment, endTime.Sub(startTime), err)
+	}
+	if err != nil && !w.ReportFailures {
+		return false
+	}
+
+	completion := &TaskCompletion{
+		AssignmentID: assignment.ID,
+		RuleID:       assignment.RuleID,
+		Success:      err == nil,
+		StartTime:    startTime,
+		EndTime:      endTime,
+	}
+	if err != nil {
+		completion.Logs = err.Error()
+	}
+	if completeErr := w.Client.CompleteAssignment(context.WithoutCancel(ctx), completion); completeErr != nil {
+		if w.Hooks.OnCompleteError != nil {
+			w.Hooks.OnCompleteError(assignment, completeErr)
+		}
+	}
+	return true
+}
+
+func (w *Worker) execute(ctx context.Context, assignment *Assignment) (err error) {
+	handler, exists := w.handler(assignment.TaskType)
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrNoHandler, assignment.TaskType)
+	}
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = &TaskPanicError{AssignmentID: assignment.ID, Value: recovered, Stack: debug.Stack()}
+		}
+	}()
+	return handler(ctx, assignment)
+}
+
+func (w *Worker) backoff(failures int) time.Duration {
+	minBackoff, maxBackoff := w.MinBackoff, w.MaxBackoff
+	if minBackoff <= 0 {
+		minBackoff = time.Second
+	}
+	if maxBackoff < minBackoff {
+		maxBackoff = minBackoff
+	}
+	delay := minBackoff
+	for i := 1; i < failures && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxBackoff {
+		delay = maxBackoff
+	}
+	jitter := time.Duration(rand.Int63n(int64(delay)/2 + 1))
+	return delay/2 + jitter
+}
+
+func (w *Worker) pollInterval(idlePolls int) time.Duration {
+	interval, maxInterval := w.PollInterval, w.MaxPollInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	if maxInterval < interval {
+		maxInterval = interval
+	}
+	for i := 1; i < idlePolls && interval < maxInterval; i++ {
+		interval *= 2
+	}
+	if interval > maxInterval {
+		interval = maxInterval
+	}
+	return interval
+}
+
+func (w *Worker) wait(ctx context.Context, delay time.Duration) {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
@@ -0,0 +1,238 @@
+package infuzu_test
+
+import (
+	"context"
+	"errors"
+	clockwise "github.com/infuzu/infuzu-go-sdk/infuzu/clockwise"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for condition")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func runWorker(t *testing.T, worker *clockwise.Worker) context.CancelFunc {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- worker.Run(ctx) }()
+	stopped := false
+	stop := func() {
+		if stopped {
+			return
+		}
+		stopped = true
+		cancel()
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Errorf("worker returned %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Error("worker did not stop after cancellation")
+		}
+	}
+	t.Cleanup(stop)
+	return stop
+}
+
+func TestWorkerProcessesAssignments(t *testing.T) {
+	server := newServer(t)
+	worker := clockwise.NewWorker(server.Client(nil))
+	worker.PollInterval = 5 * time.Millisecond
+	worker.ReportFailures = true
+
+	var mutex sync.Mutex
+	started := map[string]bool{}
+	finished := map[string]error{}
+	worker.Hooks.OnTaskStart = func(assignment *clockwise.Assignment) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		started[assignment.ID] = true
+	}
+	worker.Hooks.OnTaskFinish = func(assignment *clockwise.Assignment, _ time.Duration, err error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		finished[assignment.ID] = err
+	}
+	worker.Handle("ok", func(ctx context.Context, assignment *clockwise.Assignment) error { return nil })
+	worker.Handle("fail", func(ctx context.Context, assignment *clockwise.Assignment) error {
+		return errors.New("task failed")
+	})
+	worker.Handle("panic", func(ctx context.Context, assignment *clockwise.Assignment) error {
+		panic("handler exploded")
+	})
+	server.Enqueue(
+		clockwise.Assignment{ID: "ok", RuleID: "rule", TaskType: "ok"},
+		clockwise.Assignment{ID: "fail", RuleID: "rule", TaskType: "fail"},
+		clockwise.Assignment{ID: "panic", RuleID: "rule", TaskType: "panic"},
+		clockwise.Assignment{ID: "unknown", RuleID: "rule", TaskType: "unknown"},
+	)
+
+	stop := runWorker(t, worker)
+	waitFor(t, func() bool { return len(server.Completions()) == 4 })
+	stop()
+
+	results := map[string]clockwise.TaskCompletion{}
+	for _, completion := range server.Completions() {
+		results[completion.AssignmentID] = completion
+	}
+	if !results["ok"].Success || results["fail"].Success || results["panic"].Success || results["unknown"].Success {
+		t.Fatalf("unexpected completion results %+v", results)
+	}
+	if !strings.Contains(results["panic"].Logs, "handler exploded") {
+		t.Fatalf("panic was not reported in the logs: %q", results["panic"].Logs)
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(started) != 4 || len(finished) != 4 {
+		t.Fatalf("hooks saw %d starts and %d finishes", len(started), len(finished))
+	}
+	var panicErr *clockwise.TaskPanicError
+	if !errors.As(finished["panic"], &panicErr) || len(panicErr.Stack) == 0 {
+		t.Fatalf("expected a TaskPanicError, got %v", finished["panic"])
+	}
+	if !errors.Is(finished["unknown"], clockwise.ErrNoHandler) {
+		t.Fatalf("expected ErrNoHandler, got %v", finished["unknown"])
+	}
+}
+
+func TestWorkerSkipsReportingFailuresByDefault(t *testing.T) {
+	server := newServer(t)
+	worker := clockwise.NewWorker(server.Client(nil))
+	worker.PollInterval = 5 * time.Millisecond
+	worker.MinBackoff = 5 * time.Millisecond
+	backoffs := make(chan time.Duration, 4)
+	worker.Hooks.OnBackoff = func(delay time.Duration) { backoffs <- delay }
+	finished := make(chan string, 2)
+	worker.Hooks.OnTaskFinish = func(assignment *clockwise.Assignment, _ time.Duration, _ error) {
+		finished <- assignment.ID
+	}
+	worker.Handle("task", func(ctx context.Context, assignment *clockwise.Assignment) error {
+		if assignment.ID == "fail" {
+			return errors.New("task failed")
+		}
+		return nil
+	})
+	server.Enqueue(
+		clockwise.Assignment{ID: "fail", TaskType: "task"},
+		clockwise.Assignment{ID: "ok", TaskType: "task"},
+	)
+
+	stop := runWorker(t, worker)
+	<-finished
+	<-finished
+	waitFor(t, func() bool { return len(server.Completions()) == 1 })
+	stop()
+	if completions := server.Completions(); len(completions) != 1 || completions[0].AssignmentID != "ok" {
+		t.Fatalf("unexpected completions %+v", completions)
+	}
+	if len(backoffs) != 1 {
+		t.Fatalf("expected one backoff after the unreported failure, got %d", len(backoffs))
+	}
+}
+
+func TestWorkerSlowsDownWhenIdle(t *testing.T) {
+	server := newServer(t)
+	worker := clockwise.NewWorker(server.Client(nil))
+	worker.PollInterval = 5 * time.Millisecond
+	worker.MaxPollInterval = 20 * time.Millisecond
+
+	var mutex sync.Mutex
+	var polls []time.Time
+	worker.Hooks.OnPoll = func(_ *clockwise.Assignment, _ error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		polls = append(polls, time.Now())
+	}
+	snapshot := func() []time.Time {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return append([]time.Time(nil), polls...)
+	}
+
+	stop := runWorker(t, worker)
+	waitFor(t, func() bool { return len(snapshot()) >= 8 })
+	stop()
+
+	recorded := snapshot()
+	for i, floor := range []time.Duration{5, 10, 20, 20, 20, 20, 20} {
+		floor *= time.Millisecond
+		if gap := recorded[i+1].Sub(recorded[i]); gap < floor {
+			t.Fatalf("idle poll %d waited %s, expected at least %s", i, gap, floor)
+		}
+	}
+	if gap := recorded[7].Sub(recorded[6]); gap > 150*time.Millisecond {
+		t.Fatalf("idle polling was not capped, waited %s", gap)
+	}
+}
+
+func TestWorkerBacksOffOnErrorsAndResetsWhenIdle(t *testing.T) {
+	server := newServer(t)
+	worker := clockwise.NewWorker(server.Client(nil))
+	worker.PollInterval = 5 * time.Millisecond
+	worker.MinBackoff = 10 * time.Millisecond
+	worker.MaxBackoff = 40 * time.Millisecond
+
+	var mutex sync.Mutex
+	var delays []time.Duration
+	idlePolls := 0
+	worker.Hooks.OnBackoff = func(delay time.Duration) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		delays = append(delays, delay)
+	}
+	worker.Hooks.OnPoll = func(_ *clockwise.Assignment, err error) {
+		if errors.Is(err, clockwise.ErrNoAssignment) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			idlePolls++
+		}
+	}
+	snapshot := func() ([]time.Duration, int) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return append([]time.Duration(nil), delays...), idlePolls
+	}
+
+	server.FailNext(4, http.StatusInternalServerError)
+	stop := runWorker(t, worker)
+	waitFor(t, func() bool {
+		recorded, idle := snapshot()
+		return len(recorded) == 4 && idle >= 3
+	})
+	server.FailNext(1, http.StatusInternalServerError)
+	waitFor(t, func() bool {
+		recorded, _ := snapshot()
+		return len(recorded) == 5
+	})
+	_, idleBefore := snapshot()
+	waitFor(t, func() bool {
+		_, idle := snapshot()
+		return idle >= idleBefore+3
+	})
+	stop()
+
+	recorded, _ := snapshot()
+	if len(recorded) != 5 {
+		t.Fatalf("idle polls should not back off, got %d backoffs", len(recorded))
+	}
+	for i, ceiling := range []time.Duration{10, 20, 40, 40, 10} {
+		ceiling *= time.Millisecond
+		if recorded[i] < ceiling/2 || recorded[i] > ceiling {
+			t.Fatalf("backoff %d was %s, expected between %s and %s", i, recorded[i], ceiling/2, ceiling)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package infuzu
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	constants "github.com/infuzu/infuzu-go-sdk/infuzu/constants"
+	requests "github.com/infuzu/infuzu-go-sdk/infuzu/requests"
+	utils "github.com/infuzu/infuzu-go-sdk/infuzu/utils"
+	"io"
+	"net/http"
+	"time"
+)
+
+var ErrNotFound = errors.New("infuzu/cogitobot/cogitobot.go document version not found")
+
+type DocumentVersion struct {
+	ID            string     `json:"id"`
+	DocumentID    string     `json:"document_id"`
+	VersionNumber int        `json:"version_number"`
+	Title         string     `json:"title,omitempty"`
+	ContentType   string     `json:"content_type,omitempty"`
+	Size          int64      `json:"size,omitempty"`
+	Checksum      string     `json:"checksum,omitempty"`
+	Content       string     `json:"content,omitempty"`
+	CreatedAt     *time.Time `json:"created_at,omitempty"`
+}
+
+type DocumentContent struct {
+	io.ReadCloser
+	ContentType   string
+	ContentLength int64
+}
+
+type Client struct {
+	Session *requests.SignatureSession
+	BaseURL string
+}
+
+func NewClient(session *requests.SignatureSession) *Client {
+	if session == nil {
+		session = requests.SignedClient
+	}
+	return &Client{Session: session}
+}
+
+var DefaultClient = NewClient(nil)
+
+func (c *Client) baseURL() string {
+	if c.BaseURL != "" {
+		return c.BaseURL
+	}
+	return constants.CogitobotBaseUrl()
+}
+
+func (c *Client) documentVersionURL(documentVersionID string) (string, error) {
+	if documentVersionID == "" {
+		return "", errors.New("infuzu/cogitobot/cogitobot.go document version id cannot be empty")
+	}
+	return utils.BuildURL(
+		c.baseURL(),
+		constants.CogitobotRetrieveDocumentVersionEndpoint(),
+		map[string]interface{}{"document_version_id": documentVersionID},
+	)
+}
+
+func (c *Client) RetrieveDocumentVersion(ctx context.Context, documentVersionID string) (*DocumentVersion, error) {
+	url, err := c.documentVersionURL(documentVersionID)
+	if err != nil {
+		return nil, err
+	}
+	var documentVersion DocumentVersion
+	var statusCode int
+	statusCode, err = c.Session.RequestJSON(ctx, "GET", url, nil, &documentVersion)
+	if statusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %w", ErrNotFound, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &documentVersion, nil
+}
+
+func (c *Client) OpenDocumentVersion(ctx context.Context, documentVersionID string) (*DocumentContent, error) {
+	url, err := c.documentVersionURL(documentVersionID)
+	if err != nil {
+		return nil, err
+	}
+	var resp *http.Response
+	resp, err = c.Session.StreamContext(ctx, "GET", url, nil, map[string]string{"Accept": "application/octet-stream"})
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		err = requests.NewAPIError("infuzu/cogitobot/cogitobot.go", "GET", url, resp)
+		_ = resp.Body.Close()
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("%w: %w", ErrNotFound, err)
+		}
+		return nil, err
+	}
+	return &DocumentContent{
+		ReadCloser:    resp.Body,
+		ContentType:   resp.Header.Get("Content-Type"),
+		ContentLength: resp.ContentLength,
+	}, nil
+}
+
+func (c *Client) DownloadDocumentVersion(
+	ctx context.Context, documentVersionID string, destination io.Writer,
+) (written int64, err error) {
+	var content *DocumentContent
+	content, err = c.OpenDocumentVersion(ctx, documentVersionID)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if cerr := content.Close(); cerr != nil && err == nil {
+			err = fmt.Errorf("failed to close response body: %w", cerr)
+		}
+	}()
+	return io.Copy(destination, content)
+}
+
+func RetrieveDocumentVersion(ctx context.Context, documentVersionID string) (*DocumentVersion, error) {
+	return DefaultClient.RetrieveDocumentVersion(ctx, documentVersionID)
+}
+
+func OpenDocumentVersion(ctx context.Context, documentVersionID string) (*DocumentContent, error) {
+	return DefaultClient.OpenDocumentVersion(ctx, documentVersionID)
+}
+
+func DownloadDocumentVersion(ctx context.Context, documentVersionID string, destination io.Writer) (int64, error) {
+	return DefaultClient.DownloadDocumentVersion(ctx, documentVersionID, destination)
+}
@@ -0,0 +1,79 @@
+package infuzu
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	requests "github.com/infuzu/infuzu-go-sdk/infuzu/requests"
+	requeststest "github.com/infuzu/infuzu-go-sdk/infuzu/requests/requeststest"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	server := requeststest.NewServer(handler)
+	t.Cleanup(server.Close)
+	client := NewClient(server.Session())
+	client.BaseURL = server.BaseURL()
+	return client
+}
+
+func slowBody(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/octet-stream")
+	_, _ = w.Write([]byte(`{"id":`))
+	w.(http.Flusher).Flush()
+	select {
+	case <-time.After(200 * time.Millisecond):
+	case <-r.Context().Done():
+		return
+	}
+	_, _ = w.Write([]byte(`"version-1"}`))
+}
+
+func TestDownloadOutlivesRequestTimeout(t *testing.T) {
+	client := newTestClient(t, slowBody)
+	client.Session.RequestTimeout = 50 * time.Millisecond
+
+	var destination bytes.Buffer
+	written, err := client.DownloadDocumentVersion(context.Background(), "version-1", &destination)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if written != int64(destination.Len()) || destination.String() != `{"id":"version-1"}` {
+		t.Fatalf("unexpected download %q (%d bytes)", destination.String(), written)
+	}
+}
+
+func TestDownloadHonoursCallerDeadline(t *testing.T) {
+	client := newTestClient(t, slowBody)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	var destination bytes.Buffer
+	if _, err := client.DownloadDocumentVersion(ctx, "version-1", &destination); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the caller's deadline to stop the download, got %v", err)
+	}
+}
+
+func TestRetrieveDocumentVersionAppliesRequestTimeout(t *testing.T) {
+	client := newTestClient(t, slowBody)
+	client.Session.RequestTimeout = 50 * time.Millisecond
+	if _, err := client.RetrieveDocumentVersion(context.Background(), "version-1"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the request timeout to apply, got %v", err)
+	}
+}
+
+func TestOpenDocumentVersionReportsNotFound(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "missing", http.StatusNotFound)
+	})
+	_, err := client.OpenDocumentVersion(context.Background(), "version-1")
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if apiError, ok := requests.AsAPIError(err); !ok || apiError.Body != "missing" {
+		t.Fatalf("expected the API error to be preserved, got %v", err)
+	}
+}
@@ -9,3 +9,13 @@ func SetPrivateKey(key string) {
 func GetSetPrivateKey() string {
 	return privateKey
 }
+
+var privateKeyPassphrase []byte
+
+func SetPrivateKeyPassphrase(passphrase []byte) {
+	privateKeyPassphrase = passphrase
+}
+
+func GetSetPrivateKeyPassphrase() []byte {
+	return privateKeyPassphrase
+}
@@ -37,13 +37,13 @@ var IKeysKeyPairEndpoint = utils.PreconfiguredGetEnv(
 )
 
 var CogitobotBaseUrl = utils.PreconfiguredGetEnv("COGITOBOT_BASE_URL", "https://cogitobot.infuzu.com/")
-var CogitobotRetrieveDocumentVersionEndpoint = utils.GetEnv(
+var CogitobotRetrieveDocumentVersionEndpoint = utils.PreconfiguredGetEnv(
 	"COGITOBOT_RETRIEVE_DOCUMENT_VERSION_ENDPOINT",
 	"internal/document-version/<str:document_version_id>/",
 )
 
 var AccessBaseUrl = utils.PreconfiguredGetEnv("ACCESS_BASE_URL", "https://accounts.infuzu.com/")
-var AccessRetrieveObjectAccessProfileEndpoint = utils.GetEnv(
+var AccessRetrieveObjectAccessProfileEndpoint = utils.PreconfiguredGetEnv(
 	"ACCESS_RETRIEVE_OBJECT_ACCESS_PROFILE_ENDPOINT",
 	"access/object-access-profile/<str:user_id>/<str:object_type>/",
 )
@@ -0,0 +1,91 @@
+package infuzu
+
+import (
+	"errors"
+	access "github.com/infuzu/infuzu-go-sdk/infuzu/access"
+	shortcuts "github.com/infuzu/infuzu-go-sdk/infuzu/authentication/shortcuts"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+type UserIDExtractor func(c echo.Context) (string, error)
+
+// UserIDFromHeader trusts the header only when it was set by a verified
+// application: VerifyAndIdentifyMiddleware must run first, and the caller must
+// list the header among the signed headers of a 2.0 request signature.
+func UserIDFromHeader(headerName string) UserIDExtractor {
+	return func(c echo.Context) (string, error) {
+		return access.UserIDFromSignedHeader(c.Get("application"), c.Request().Header, headerName)
+	}
+}
+
+// UserIDFromParam trusts the path parameter only when VerifyAndIdentifyMiddleware
+// verified the application and the request carries a 2.0 signature, which
+// covers the path.
+func UserIDFromParam(paramName string) UserIDExtractor {
+	return func(c echo.Context) (string, error) {
+		return access.UserIDFromSignedPath(
+			c.Get("application"), c.Request().Header.Get(shortcuts.SignatureHeaderName), c.Param(paramName),
+		)
+	}
+}
+
+func UserIDFromContext(key string) UserIDExtractor {
+	return func(c echo.Context) (string, error) {
+		userID, _ := c.Get(key).(string)
+		if userID == "" {
+			return "", errors.New("infuzu/integrations/echo/authorization.go user id is not set on the context")
+		}
+		return userID, nil
+	}
+}
+
+func RequireUserPermission(extractor UserIDExtractor, permission string) echo.MiddlewareFunc {
+	return RequireUserPermissionWith(access.DefaultAuthorizer, extractor, permission)
+}
+
+func RequireUserPermissionWith(
+	authorizer *access.Authorizer, extractor UserIDExtractor, permission string,
+) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			userID, err := extractor(c)
+			if err != nil || userID == "" {
+				return c.JSON(http.StatusForbidden, map[string]string{"error": "Access Denied - User could not be identified"})
+			}
+			var profile *access.UserAccessProfile
+			profile, err = authorizer.UserAccessProfile(c.Request().Context(), userID)
+			if status, message := access.PermissionFailure(err, profile.HasPermission(permission)); status != 0 {
+				return c.JSON(status, map[string]string{"error": message})
+			}
+			c.Set("user_id", userID)
+			c.Set("access_profile", profile)
+			return next(c)
+		}
+	}
+}
+
+func RequireObjectPermission(extractor UserIDExtractor, objectType string, permission string) echo.MiddlewareFunc {
+	return RequireObjectPermissionWith(access.DefaultAuthorizer, extractor, objectType, permission)
+}
+
+func RequireObjectPermissionWith(
+	authorizer *access.Authorizer, extractor UserIDExtractor, objectType string, permission string,
+) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			userID, err := extractor(c)
+			if err != nil || userID == "" {
+				return c.JSON(http.StatusForbidden, map[string]string{"error": "Access Denied - User could not be identified"})
+			}
+			var profile *access.ObjectAccessProfile
+			profile, err = authorizer.ObjectAccessProfile(c.Request().Context(), userID, objectType)
+			if status, message := access.PermissionFailure(err, profile.HasPermission(permission)); status != 0 {
+				return c.JSON(status, map[string]string{"error": message})
+			}
+			c.Set("user_id", userID)
+			c.Set("object_access_profile", profile)
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package infuzu
+
+import (
+	access "github.com/infuzu/infuzu-go-sdk/infuzu/access"
+	base "github.com/infuzu/infuzu-go-sdk/infuzu/authentication/base"
+	shortcuts "github.com/infuzu/infuzu-go-sdk/infuzu/authentication/shortcuts"
+	requeststest "github.com/infuzu/infuzu-go-sdk/infuzu/requests/requeststest"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newAuthorizer(t *testing.T, keys *base.IKeys) *access.Authorizer {
+	t.Helper()
+	server := requeststest.NewServerWithKeys(keys, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/access/user-access-profile/"), "/")
+		switch userID {
+		case "reader":
+			requeststest.WriteJSON(w, http.StatusOK, access.UserAccessProfile{UserID: userID, Permissions: access.Permissions{"read"}})
+		case "outage":
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		case "rejected":
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	client := access.NewClient(server.Session())
+	client.BaseURL = server.BaseURL()
+	return access.NewAuthorizer(client, time.Minute, 10)
+}
+
+func userRequest(t *testing.T, keys *base.IKeys, userID string, signedHeaders []string) *http.Request {
+	t.Helper()
+	request := httptest.NewRequest("GET", "/documents", nil)
+	request.Header.Set("X-User-ID", userID)
+	if signedHeaders == nil {
+		return request
+	}
+	signature, err := keys.PrivateKey.SignRequest(base.NewSignableRequest(request, nil), signedHeaders, "2.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	request.Header.Set(shortcuts.SignatureHeaderName, signature)
+	return request
+}
+
+func TestRequireUserPermissionFromSignedHeader(t *testing.T) {
+	keys := newKeyService(t)
+	server := echo.New()
+	server.Use(VerifyAndIdentifyMiddleware)
+	server.GET(
+		"/documents",
+		func(c echo.Context) error { return c.String(http.StatusOK, c.Get("user_id").(string)) },
+		RequireUserPermissionWith(newAuthorizer(t, keys), UserIDFromHeader("X-User-ID"), "read"),
+	)
+
+	cases := map[string]struct {
+		request  *http.Request
+		expected int
+	}{
+		"signed user header":   {userRequest(t, keys, "reader", []string{"X-User-ID"}), http.StatusOK},
+		"unsigned user header": {userRequest(t, keys, "reader", []string{}), http.StatusForbidden},
+		"unsigned request":     {userRequest(t, keys, "reader", nil), http.StatusForbidden},
+		"unknown user":         {userRequest(t, keys, "stranger", []string{"X-User-ID"}), http.StatusForbidden},
+		"access outage":        {userRequest(t, keys, "outage", []string{"X-User-ID"}), http.StatusServiceUnavailable},
+		"rejected credentials": {userRequest(t, keys, "rejected", []string{"X-User-ID"}), http.StatusServiceUnavailable},
+	}
+	for name, testCase := range cases {
+		t.Run(name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, testCase.request)
+			if response.Code != testCase.expected {
+				t.Fatalf("expected %d, got %d: %s", testCase.expected, response.Code, response.Body.String())
+			}
+			if testCase.expected == http.StatusOK && response.Body.String() != "reader" {
+				t.Fatalf("unexpected user id %q", response.Body.String())
+			}
+		})
+	}
+}
+
+func TestForgedUserHeaderIsRejected(t *testing.T) {
+	keys := newKeyService(t)
+	server := echo.New()
+	server.Use(VerifyAndIdentifyMiddleware)
+	server.GET(
+		"/documents",
+		func(c echo.Context) error { return c.NoContent(http.StatusOK) },
+		RequireUserPermissionWith(newAuthorizer(t, keys), UserIDFromHeader("X-User-ID"), "read"),
+	)
+
+	request := userRequest(t, keys, "stranger", []string{"X-User-ID"})
+	request.Header.Set("X-User-ID", "reader")
+	response := httptest.NewRecorder()
+	server.ServeHTTP(response, request)
+	if response.Code != http.StatusForbidden {
+		t.Fatalf("a rewritten user header was accepted: %d", response.Code)
+	}
+}
+
+func TestRequireUserPermissionFromSignedPath(t *testing.T) {
+	keys := newKeyService(t)
+	server := echo.New()
+	server.Use(VerifyAndIdentifyMiddleware)
+	server.GET(
+		"/users/:id/documents",
+		func(c echo.Context) error { return c.String(http.StatusOK, c.Get("user_id").(string)) },
+		RequireUserPermissionWith(newAuthorizer(t, keys), UserIDFromParam("id"), "read"),
+	)
+
+	signed := httptest.NewRequest("GET", "/users/reader/documents", nil)
+	signature, err := keys.PrivateKey.SignRequest(base.NewSignableRequest(signed, nil), nil, "2.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed.Header.Set(shortcuts.SignatureHeaderName, signature)
+	bodyOnly := httptest.NewRequest("GET", "/users/reader/documents", nil)
+	signature, err = keys.PrivateKey.SignMessage("", "1.2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bodyOnly.Header.Set(shortcuts.SignatureHeaderName, signature)
+
+	cases := map[string]struct {
+		request  *http.Request
+		expected int
+	}{
+		"request-bound signature": {signed, http.StatusOK},
+		"body-only signature":     {bodyOnly, http.StatusForbidden},
+		"unsigned request":        {httptest.NewRequest("GET", "/users/reader/documents", nil), http.StatusForbidden},
+	}
+	for name, testCase := range cases {
+		t.Run(name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, testCase.request)
+			if response.Code != testCase.expected {
+				t.Fatalf("expected %d, got %d: %s", testCase.expected, response.Code, response.Body.String())
+			}
+		})
+	}
+}
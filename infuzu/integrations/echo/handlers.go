@@ -1,8 +1,10 @@
 package infuzu
 
 import (
+	"bytes"
 	"fmt"
 	authenticate "github.com/infuzu/infuzu-go-sdk/infuzu/authentication/authenticate"
+	base "github.com/infuzu/infuzu-go-sdk/infuzu/authentication/base"
 	shortcuts "github.com/infuzu/infuzu-go-sdk/infuzu/authentication/shortcuts"
 	"github.com/labstack/echo/v4"
 	"io"
@@ -49,12 +51,15 @@ func EnsureMessageIsValidFromPublicKey(publicKey interface{}) echo.MiddlewareFun
 			if err != nil {
 				return fmt.Errorf("error reading request body: %w", err)
 			}
-			c.Request().Body = http.MaxBytesReader(c.Response(), c.Request().Body, int64(len(message)))
+			c.Request().Body = io.NopCloser(bytes.NewBuffer(message))
 			var isValid bool
-			isValid, err = authenticate.VerifyDiverseMessageSignature(string(message), signature, publicKey)
+			isValid, err = authenticate.VerifyDiverseRequestSignatureContext(
+				c.Request().Context(), base.NewSignableRequest(c.Request(), message), signature, publicKey,
+			)
 			if err != nil || !isValid {
 				return c.JSON(http.StatusForbidden, map[string]string{"error": "Access Denied - Message is not properly signed"})
 			}
+			c.SetRequest(c.Request().WithContext(shortcuts.ContextWithReplayChecked(c.Request().Context(), signature)))
 			return next(c)
 		}
 	}
@@ -68,10 +73,13 @@ func EnsureMessageIsValidFromPublicKeys(publicKeys []interface{}) echo.Middlewar
 			if err != nil {
 				return fmt.Errorf("error reading request body: %w", err)
 			}
-			c.Request().Body = http.MaxBytesReader(c.Response(), c.Request().Body, int64(len(message)))
+			c.Request().Body = io.NopCloser(bytes.NewBuffer(message))
 			for _, publicKey := range publicKeys {
-				isValid, err := authenticate.VerifyDiverseMessageSignature(string(message), signature, publicKey)
+				isValid, err := authenticate.VerifyDiverseRequestSignatureContext(
+					c.Request().Context(), base.NewSignableRequest(c.Request(), message), signature, publicKey,
+				)
 				if err == nil && isValid {
+					c.SetRequest(c.Request().WithContext(shortcuts.ContextWithReplayChecked(c.Request().Context(), signature)))
 					return next(c)
 				}
 			}
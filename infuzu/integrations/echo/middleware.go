@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	authenticate "github.com/infuzu/infuzu-go-sdk/infuzu/authentication/authenticate"
+	base "github.com/infuzu/infuzu-go-sdk/infuzu/authentication/base"
 	infuzu "github.com/infuzu/infuzu-go-sdk/infuzu/authentication/requests"
 	shortcuts "github.com/infuzu/infuzu-go-sdk/infuzu/authentication/shortcuts"
 	"github.com/labstack/echo/v4"
@@ -19,10 +20,13 @@ func VerifyAndIdentifyMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 		}
 		c.Request().Body = io.NopCloser(bytes.NewBuffer(message))
 		var application *infuzu.Application
-		application, err = authenticate.ConvertMessageSignatureToApplicationAndVerify(signature, string(message))
+		application, err = authenticate.ConvertRequestSignatureToApplicationAndVerifyContext(
+			c.Request().Context(), signature, base.NewSignableRequest(c.Request(), message),
+		)
 		if err != nil {
 			c.Set("application", nil)
 		} else {
+			c.SetRequest(c.Request().WithContext(shortcuts.ContextWithReplayChecked(c.Request().Context(), signature)))
 			c.Set("application", application)
 		}
 		return next(c)
@@ -0,0 +1,130 @@
+package infuzu
+
+import (
+	"bytes"
+	base "github.com/infuzu/infuzu-go-sdk/infuzu/authentication/base"
+	replay "github.com/infuzu/infuzu-go-sdk/infuzu/authentication/replay"
+	shortcuts "github.com/infuzu/infuzu-go-sdk/infuzu/authentication/shortcuts"
+	requeststest "github.com/infuzu/infuzu-go-sdk/infuzu/requests/requeststest"
+	"github.com/labstack/echo/v4"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newKeyService(t *testing.T) *base.IKeys {
+	t.Helper()
+	keys, err := base.GenerateIKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey, err := keys.PublicKey.ToBase64()
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := requeststest.NewServerWithKeys(keys, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requeststest.WriteJSON(w, http.StatusOK, map[string]interface{}{
+			"valid": map[string]interface{}{
+				"id":             keys.ID,
+				"name":           "test key",
+				"public_key_b64": publicKey,
+				"application":    map[string]interface{}{"id": "application-id", "name": "Test", "is_internal": true},
+			},
+		})
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("INFUZU_SECRET_KEY", server.PrivateKey)
+	t.Setenv("INFUZU_KEYS_BASE_URL", server.BaseURL())
+	return keys
+}
+
+func signedRequest(t *testing.T, keys *base.IKeys, method, target string, body []byte) *http.Request {
+	t.Helper()
+	request := httptest.NewRequest(method, target, bytes.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	signature, err := keys.PrivateKey.SignRequest(base.NewSignableRequest(request, body), []string{"Content-Type"}, "2.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	request.Header.Set(shortcuts.SignatureHeaderName, signature)
+	return request
+}
+
+func TestVerifyAndIdentifyMiddlewareBindsRequest(t *testing.T) {
+	keys := newKeyService(t)
+	server := echo.New()
+	server.Use(VerifyAndIdentifyMiddleware)
+	handler := func(c echo.Context) error {
+		body, _ := io.ReadAll(c.Request().Body)
+		return c.String(http.StatusOK, string(body))
+	}
+	server.POST("/items", handler, EnsureThereIsValidApplication)
+	server.POST("/other", handler, EnsureThereIsValidApplication)
+
+	body := []byte(`{"name":"item"}`)
+	response := httptest.NewRecorder()
+	server.ServeHTTP(response, signedRequest(t, keys, "POST", "/items?page=1", body))
+	if response.Code != http.StatusOK || response.Body.String() != string(body) {
+		t.Fatalf("signed request was rejected: %d %s", response.Code, response.Body.String())
+	}
+
+	replays := map[string]func(r *http.Request) *http.Request{
+		"other path": func(r *http.Request) *http.Request {
+			replayed := httptest.NewRequest("POST", "/other?page=1", bytes.NewReader(body))
+			replayed.Header = r.Header.Clone()
+			return replayed
+		},
+		"other query": func(r *http.Request) *http.Request {
+			replayed := httptest.NewRequest("POST", "/items?page=2", bytes.NewReader(body))
+			replayed.Header = r.Header.Clone()
+			return replayed
+		},
+		"other body": func(r *http.Request) *http.Request {
+			replayed := httptest.NewRequest("POST", "/items?page=1", bytes.NewReader([]byte(`{}`)))
+			replayed.Header = r.Header.Clone()
+			return replayed
+		},
+		"unsigned": func(r *http.Request) *http.Request {
+			return httptest.NewRequest("POST", "/items?page=1", bytes.NewReader(body))
+		},
+	}
+	for name, replay := range replays {
+		t.Run(name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, replay(signedRequest(t, keys, "POST", "/items?page=1", body)))
+			if response.Code != http.StatusForbidden {
+				t.Fatalf("expected 403, got %d", response.Code)
+			}
+		})
+	}
+}
+
+func TestChainedVerificationRecordsTheSignatureOnce(t *testing.T) {
+	shortcuts.SetReplayStore(replay.NewMemoryReplayStore())
+	defer shortcuts.SetReplayStore(nil)
+	keys := newKeyService(t)
+	server := echo.New()
+	server.Use(VerifyAndIdentifyMiddleware)
+	server.POST(
+		"/items",
+		func(c echo.Context) error { return c.NoContent(http.StatusNoContent) },
+		EnsureThereIsValidApplication,
+		EnsureMessageIsValidFromPublicKey(keys.PublicKey),
+		EnsureMessageIsValidFromPublicKeys([]interface{}{keys.PublicKey}),
+	)
+
+	request := signedRequest(t, keys, "POST", "/items", []byte(`{"name":"item"}`))
+	replayed := request.Clone(request.Context())
+	replayed.Body = io.NopCloser(bytes.NewReader([]byte(`{"name":"item"}`)))
+	response := httptest.NewRecorder()
+	server.ServeHTTP(response, request)
+	if response.Code != http.StatusNoContent {
+		t.Fatalf("chained verification rejected a fresh request: %d %s", response.Code, response.Body.String())
+	}
+	response = httptest.NewRecorder()
+	server.ServeHTTP(response, replayed)
+	if response.Code != http.StatusForbidden {
+		t.Fatalf("expected the replayed request to be rejected, got %d", response.Code)
+	}
+}
@@ -0,0 +1,33 @@
+package infuzu
+
+import (
+	subscriptions "github.com/infuzu/infuzu-go-sdk/infuzu/subscriptions"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+func EnsureActiveSubscription(extractor UserIDExtractor, allowedPlanIDs []string) echo.MiddlewareFunc {
+	return EnsureActiveSubscriptionWith(subscriptions.DefaultChecker, extractor, allowedPlanIDs, true)
+}
+
+func EnsureActiveSubscriptionWith(
+	checker *subscriptions.Checker, extractor UserIDExtractor, allowedPlanIDs []string, allowFreeTrial bool,
+) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			userID, err := extractor(c)
+			if err != nil || userID == "" {
+				return c.JSON(http.StatusForbidden, map[string]string{"error": "Access Denied - User could not be identified"})
+			}
+			var entitlement *subscriptions.Entitlement
+			entitlement, err = checker.ActiveEntitlement(c.Request().Context(), userID, allowedPlanIDs, allowFreeTrial)
+			if status, message := subscriptions.EntitlementFailure(err); status != 0 {
+				return c.JSON(status, map[string]string{"error": message})
+			}
+			c.Set("user_id", userID)
+			c.Set("user_subscription", entitlement.Subscription)
+			c.Set("free_trial", entitlement.FreeTrial)
+			return next(c)
+		}
+	}
+}
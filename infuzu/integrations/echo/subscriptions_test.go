@@ -0,0 +1,64 @@
+package infuzu
+
+import (
+	base "github.com/infuzu/infuzu-go-sdk/infuzu/authentication/base"
+	requeststest "github.com/infuzu/infuzu-go-sdk/infuzu/requests/requeststest"
+	subscriptions "github.com/infuzu/infuzu-go-sdk/infuzu/subscriptions"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newSubscriptionChecker(t *testing.T, keys *base.IKeys) *subscriptions.Checker {
+	t.Helper()
+	service := requeststest.NewStub()
+	service.Set("/subscriptions/user-subscriptions/?user_id=subscriber", []subscriptions.UserSubscription{
+		{ID: "current", UserID: "subscriber", SubscriptionPlanID: "monthly", Status: subscriptions.UserSubscriptionStatusActive},
+	}, 0)
+	service.Set("/subscriptions/user-subscriptions/?user_id=lapsed", []subscriptions.UserSubscription{
+		{ID: "canceled", UserID: "lapsed", SubscriptionPlanID: "monthly", Status: subscriptions.UserSubscriptionStatusCanceled},
+	}, 0)
+	service.Set("/subscriptions/user-subscriptions/?user_id=outage", nil, http.StatusServiceUnavailable)
+	server := requeststest.NewServerWithKeys(keys, service)
+	t.Cleanup(server.Close)
+	client := subscriptions.NewClient(server.Session())
+	client.BaseURL = server.BaseURL()
+	return subscriptions.NewChecker(client, time.Minute, 10)
+}
+
+func TestEnsureActiveSubscription(t *testing.T) {
+	keys := newKeyService(t)
+	server := echo.New()
+	server.Use(VerifyAndIdentifyMiddleware)
+	server.GET(
+		"/documents",
+		func(c echo.Context) error {
+			return c.String(http.StatusOK, c.Get("user_subscription").(*subscriptions.UserSubscription).ID)
+		},
+		EnsureActiveSubscriptionWith(newSubscriptionChecker(t, keys), UserIDFromHeader("X-User-ID"), []string{"monthly"}, false),
+	)
+
+	cases := map[string]struct {
+		request  *http.Request
+		expected int
+	}{
+		"subscriber":           {userRequest(t, keys, "subscriber", []string{"X-User-ID"}), http.StatusOK},
+		"unsigned user header": {userRequest(t, keys, "subscriber", []string{}), http.StatusForbidden},
+		"lapsed subscription":  {userRequest(t, keys, "lapsed", []string{"X-User-ID"}), http.StatusForbidden},
+		"subscription outage":  {userRequest(t, keys, "outage", []string{"X-User-ID"}), http.StatusServiceUnavailable},
+	}
+	for name, testCase := range cases {
+		t.Run(name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, testCase.request)
+			if response.Code != testCase.expected {
+				t.Fatalf("expected %d, got %d: %s", testCase.expected, response.Code, response.Body.String())
+			}
+			if testCase.expected == http.StatusOK && response.Body.String() != "current" {
+				t.Fatalf("unexpected subscription %q", response.Body.String())
+			}
+		})
+	}
+}
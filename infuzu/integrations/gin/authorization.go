@@ -0,0 +1,99 @@
+package infuzu
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	access "github.com/infuzu/infuzu-go-sdk/infuzu/access"
+	shortcuts "github.com/infuzu/infuzu-go-sdk/infuzu/authentication/shortcuts"
+	"net/http"
+)
+
+type UserIDExtractor func(c *gin.Context) (string, error)
+
+// UserIDFromHeader trusts the header only when it was set by a verified
+// application: VerifyAndIdentifyMiddleware must run first, and the caller must
+// list the header among the signed headers of a 2.0 request signature.
+func UserIDFromHeader(headerName string) UserIDExtractor {
+	return func(c *gin.Context) (string, error) {
+		application, _ := c.Get("application")
+		return access.UserIDFromSignedHeader(application, c.Request.Header, headerName)
+	}
+}
+
+// UserIDFromParam trusts the path parameter only when VerifyAndIdentifyMiddleware
+// verified the application and the request carries a 2.0 signature, which
+// covers the path.
+func UserIDFromParam(paramName string) UserIDExtractor {
+	return func(c *gin.Context) (string, error) {
+		application, _ := c.Get("application")
+		return access.UserIDFromSignedPath(application, c.GetHeader(shortcuts.SignatureHeaderName), c.Param(paramName))
+	}
+}
+
+func UserIDFromContext(key string) UserIDExtractor {
+	return func(c *gin.Context) (string, error) {
+		userID := c.GetString(key)
+		if userID == "" {
+			return "", errors.New("infuzu/integrations/gin/authorization.go user id is not set on the context")
+		}
+		return userID, nil
+	}
+}
+
+func RequireUserPermission(extractor UserIDExtractor, permission string) gin.HandlerFunc {
+	return RequireUserPermissionWith(access.DefaultAuthorizer, extractor, permission)
+}
+
+func RequireUserPermissionWith(
+	authorizer *access.Authorizer, extractor UserIDExtractor, permission string,
+) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := extractUserID(c, extractor)
+		if !ok {
+			return
+		}
+		profile, err := authorizer.UserAccessProfile(c.Request.Context(), userID)
+		if status, message := access.PermissionFailure(err, profile.HasPermission(permission)); status != 0 {
+			c.JSON(status, gin.H{"error": message})
+			c.Abort()
+			return
+		}
+		c.Set("user_id", userID)
+		c.Set("access_profile", profile)
+		c.Next()
+	}
+}
+
+func RequireObjectPermission(extractor UserIDExtractor, objectType string, permission string) gin.HandlerFunc {
+	return RequireObjectPermissionWith(access.DefaultAuthorizer, extractor, objectType, permission)
+}
+
+func RequireObjectPermissionWith(
+	authorizer *access.Authorizer, extractor UserIDExtractor, objectType string, permission string,
+) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := extractUserID(c, extractor)
+		if !ok {
+			return
+		}
+		profile, err := authorizer.ObjectAccessProfile(c.Request.Context(), userID, objectType)
+		if status, message := access.PermissionFailure(err, profile.HasPermission(permission)); status != 0 {
+			c.JSON(status, gin.H{"error": message})
+			c.Abort()
+			return
+		}
+		c.Set("user_id", userID)
+		c.Set("object_access_profile", profile)
+		c.Next()
+	}
+}
+
+func extractUserID(c *gin.Context, extractor UserIDExtractor) (string, bool) {
+	userID, err := extractor(c)
+	if err != nil || userID == "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access Denied - User could not be identified"})
+		c.Abort()
+		return "", false
+	}
+	return userID, true
+}
@@ -1,9 +1,12 @@
 package infuzu
 
 import (
+	"bytes"
 	"github.com/gin-gonic/gin"
 	authenticate "github.com/infuzu/infuzu-go-sdk/infuzu/authentication/authenticate"
+	base "github.com/infuzu/infuzu-go-sdk/infuzu/authentication/base"
 	shortcuts "github.com/infuzu/infuzu-go-sdk/infuzu/authentication/shortcuts"
+	"io"
 	"net/http"
 )
 
@@ -47,13 +50,16 @@ func EnsureMessageIsValidFromPublicKey(publicKey interface{}) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		signature := c.GetHeader(shortcuts.SignatureHeaderName)
 		message, _ := c.GetRawData()
-		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, int64(len(message)))
-		isValid, err := authenticate.VerifyDiverseMessageSignature(string(message), signature, publicKey)
+		c.Request.Body = io.NopCloser(bytes.NewBuffer(message))
+		isValid, err := authenticate.VerifyDiverseRequestSignatureContext(
+			c.Request.Context(), base.NewSignableRequest(c.Request, message), signature, publicKey,
+		)
 		if err != nil || !isValid {
 			c.JSON(http.StatusForbidden, gin.H{"error": "Access Denied - Message is not properly signed"})
 			c.Abort()
 			return
 		}
+		c.Request = c.Request.WithContext(shortcuts.ContextWithReplayChecked(c.Request.Context(), signature))
 		c.Next()
 	}
 }
@@ -62,10 +68,13 @@ func EnsureMessageIsValidFromPublicKeys(publicKeys []interface{}) gin.HandlerFun
 	return func(c *gin.Context) {
 		signature := c.GetHeader(shortcuts.SignatureHeaderName)
 		message, _ := c.GetRawData()
-		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, int64(len(message)))
+		c.Request.Body = io.NopCloser(bytes.NewBuffer(message))
 		for _, publicKey := range publicKeys {
-			isValid, err := authenticate.VerifyDiverseMessageSignature(string(message), signature, publicKey)
+			isValid, err := authenticate.VerifyDiverseRequestSignatureContext(
+				c.Request.Context(), base.NewSignableRequest(c.Request, message), signature, publicKey,
+			)
 			if err == nil && isValid {
+				c.Request = c.Request.WithContext(shortcuts.ContextWithReplayChecked(c.Request.Context(), signature))
 				c.Next()
 				return
 			}
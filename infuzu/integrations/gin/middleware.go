@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"github.com/gin-gonic/gin"
 	authenticate "github.com/infuzu/infuzu-go-sdk/infuzu/authentication/authenticate"
+	base "github.com/infuzu/infuzu-go-sdk/infuzu/authentication/base"
 	shortcuts "github.com/infuzu/infuzu-go-sdk/infuzu/authentication/shortcuts"
 	"io"
 )
@@ -13,10 +14,13 @@ func VerifyAndIdentifyMiddleware() gin.HandlerFunc {
 		signature := c.GetHeader(shortcuts.SignatureHeaderName)
 		message, _ := c.GetRawData()
 		c.Request.Body = io.NopCloser(bytes.NewBuffer(message))
-		application, err := authenticate.ConvertMessageSignatureToApplicationAndVerify(signature, string(message))
+		application, err := authenticate.ConvertRequestSignatureToApplicationAndVerifyContext(
+			c.Request.Context(), signature, base.NewSignableRequest(c.Request, message),
+		)
 		if err != nil {
 			c.Set("application", nil)
 		} else {
+			c.Request = c.Request.WithContext(shortcuts.ContextWithReplayChecked(c.Request.Context(), signature))
 			c.Set("application", application)
 		}
 		c.Next()
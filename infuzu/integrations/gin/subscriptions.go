@@ -0,0 +1,31 @@
+package infuzu
+
+import (
+	"github.com/gin-gonic/gin"
+	subscriptions "github.com/infuzu/infuzu-go-sdk/infuzu/subscriptions"
+)
+
+func EnsureActiveSubscription(extractor UserIDExtractor, allowedPlanIDs []string) gin.HandlerFunc {
+	return EnsureActiveSubscriptionWith(subscriptions.DefaultChecker, extractor, allowedPlanIDs, true)
+}
+
+func EnsureActiveSubscriptionWith(
+	checker *subscriptions.Checker, extractor UserIDExtractor, allowedPlanIDs []string, allowFreeTrial bool,
+) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := extractUserID(c, extractor)
+		if !ok {
+			return
+		}
+		entitlement, err := checker.ActiveEntitlement(c.Request.Context(), userID, allowedPlanIDs, allowFreeTrial)
+		if status, message := subscriptions.EntitlementFailure(err); status != 0 {
+			c.JSON(status, gin.H{"error": message})
+			c.Abort()
+			return
+		}
+		c.Set("user_id", userID)
+		c.Set("user_subscription", entitlement.Subscription)
+		c.Set("free_trial", entitlement.FreeTrial)
+		c.Next()
+	}
+}
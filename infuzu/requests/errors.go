@@ -0,0 +1,50 @@
+package infuzu
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+var ErrSignatureHeaderNotAllowed = errors.New("infuzu/requests/http_requests.go cannot include signature header")
+
+// APIError describes a non-2xx response. Origin names the file that made the
+// call and prefixes the message, matching the package's other errors.
+type APIError struct {
+	Origin     string
+	Method     string
+	URL        string
+	StatusCode int
+	Status     string
+	Body       string
+}
+
+func NewAPIError(origin string, method string, url string, resp *http.Response) *APIError {
+	responseBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	return &APIError{
+		Origin:     origin,
+		Method:     method,
+		URL:        url,
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Body:       strings.TrimSpace(string(responseBody)),
+	}
+}
+
+func (e *APIError) Error() string {
+	origin := e.Origin
+	if origin == "" {
+		origin = "infuzu/requests"
+	}
+	return fmt.Sprintf("%s %s %s failed: %s %s", origin, e.Method, e.URL, e.Status, e.Body)
+}
+
+func AsAPIError(err error) (*APIError, bool) {
+	var apiError *APIError
+	if errors.As(err, &apiError) {
+		return apiError, true
+	}
+	return nil, false
+}
@@ -0,0 +1,53 @@
+package infuzu
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestJSONReportsAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "  no such rule  ", http.StatusNotFound)
+	}))
+	defer server.Close()
+	privateKey, _ := mustKeyStrings(t)
+	session := NewSignatureSession(&privateKey)
+
+	statusCode, err := session.RequestJSON(context.Background(), "GET", server.URL+"/rules/1/", nil, nil)
+	apiError, ok := AsAPIError(err)
+	if !ok || statusCode != http.StatusNotFound || apiError.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected a 404 API error, got %d, %v", statusCode, err)
+	}
+	if apiError.Body != "no such rule" || apiError.Method != "GET" || apiError.URL != server.URL+"/rules/1/" {
+		t.Fatalf("unexpected API error %+v", apiError)
+	}
+	if !strings.HasPrefix(err.Error(), "infuzu/requests/json_requests.go GET ") {
+		t.Fatalf("unexpected message %q", err.Error())
+	}
+}
+
+func TestAPIErrorNamesItsOrigin(t *testing.T) {
+	var err error = &APIError{Origin: "infuzu/cogitobot/cogitobot.go", Method: "GET", URL: "/x", Status: "500"}
+	if !strings.HasPrefix(err.Error(), "infuzu/cogitobot/cogitobot.go GET /x failed") {
+		t.Fatalf("unexpected message %q", err.Error())
+	}
+	if _, ok := AsAPIError(errors.Join(errors.New("context"), err)); !ok {
+		t.Fatal("AsAPIError should unwrap wrapped errors")
+	}
+	if _, ok := AsAPIError(errors.New("other")); ok {
+		t.Fatal("AsAPIError matched an unrelated error")
+	}
+}
+
+func TestSignatureHeaderIsNotAllowed(t *testing.T) {
+	privateKey, _ := mustKeyStrings(t)
+	session := NewSignatureSession(&privateKey)
+	_, err := session.Request("GET", "http://127.0.0.1/", nil, map[string]string{"Infuzu-Signature": "forged"})
+	if !errors.Is(err, ErrSignatureHeaderNotAllowed) {
+		t.Fatalf("expected ErrSignatureHeaderNotAllowed, got %v", err)
+	}
+}
@@ -2,39 +2,99 @@ package infuzu
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
-	"fmt"
+	base "github.com/infuzu/infuzu-go-sdk/infuzu/authentication/base"
 	auth "github.com/infuzu/infuzu-go-sdk/infuzu/authentication/shortcuts"
+	"io"
+	"net"
 	"net/http"
 	"time"
 )
 
+// DefaultSignatureVersion signs only the request body, which every deployed
+// peer verifies. Set SignatureVersion to "2.0" on sessions whose peers verify
+// request-bound signatures, so a captured signature cannot be replayed against
+// another endpoint.
+const DefaultSignatureVersion = "1.2"
+
+// DefaultRequestTimeout bounds each request, including reading its response
+// body. StreamContext responses are bounded only by the caller's context.
+const DefaultRequestTimeout = 60 * time.Second
+
 type SignatureSession struct {
 	*http.Client
-	privateKey *string
+	privateKey       *string
+	signer           base.Signer
+	SignatureVersion string
+	SignedHeaders    []string
+	RequestTimeout   time.Duration
 }
 
 func newSignatureSession(privateKey *string) *SignatureSession {
 	return &SignatureSession{
 		Client: &http.Client{
-			Timeout: 60 * time.Second,
 			Transport: &http.Transport{
-				DisableKeepAlives: true,
+				DialContext:           (&net.Dialer{Timeout: 30 * time.Second}).DialContext,
+				TLSHandshakeTimeout:   10 * time.Second,
+				ResponseHeaderTimeout: DefaultRequestTimeout,
+				DisableKeepAlives:     true,
 			},
 		},
-		privateKey: privateKey,
+		privateKey:       privateKey,
+		SignatureVersion: DefaultSignatureVersion,
+		SignedHeaders:    []string{"Content-Type"},
+		RequestTimeout:   DefaultRequestTimeout,
 	}
 }
 
+func NewSignatureSession(privateKey *string) *SignatureSession {
+	return newSignatureSession(privateKey)
+}
+
+func NewSignatureSessionWithSigner(signer base.Signer) *SignatureSession {
+	session := newSignatureSession(nil)
+	session.signer = signer
+	return session
+}
+
 func (s *SignatureSession) Request(
 	method string, url string, body interface{}, headers map[string]string,
 ) (*http.Response, error) {
-	var err error
-	var privateKeyStr string
-	privateKeyStr, err = auth.GetPrivateKeyStr(s.privateKey)
+	return s.RequestContext(context.Background(), method, url, body, headers)
+}
+
+// RequestContext cancels the request once RequestTimeout has passed, even while
+// the response body is being read. Closing the body releases the timer.
+func (s *SignatureSession) RequestContext(
+	ctx context.Context, method string, url string, body interface{}, headers map[string]string,
+) (*http.Response, error) {
+	if s.RequestTimeout <= 0 {
+		return s.StreamContext(ctx, method, url, body, headers)
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.RequestTimeout)
+	resp, err := s.StreamContext(ctx, method, url, body, headers)
 	if err != nil {
+		cancel()
 		return nil, err
 	}
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// StreamContext is RequestContext without the RequestTimeout deadline, for
+// downloads that may legitimately outlast it.
+func (s *SignatureSession) StreamContext(
+	ctx context.Context, method string, url string, body interface{}, headers map[string]string,
+) (*http.Response, error) {
+	var err error
+	signer := s.signer
+	if signer == nil {
+		signer, err = auth.GetSigner(s.privateKey)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	var requestBody []byte
 
@@ -50,13 +110,7 @@ func (s *SignatureSession) Request(
 	}
 
 	var req *http.Request
-	req, err = http.NewRequest(method, url, bytes.NewBuffer(requestBody))
-	if err != nil {
-		return nil, err
-	}
-
-	var signature string
-	signature, err = auth.GenerateMessageSignature(string(requestBody), &privateKeyStr)
+	req, err = http.NewRequestWithContext(ctx, method, url, bytes.NewBuffer(requestBody))
 	if err != nil {
 		return nil, err
 	}
@@ -64,7 +118,7 @@ func (s *SignatureSession) Request(
 	_, exists := headers[auth.SignatureHeaderName]
 
 	if exists {
-		return nil, fmt.Errorf("cannot include signature header")
+		return nil, ErrSignatureHeaderNotAllowed
 	}
 
 	if requestBody != nil {
@@ -75,10 +129,33 @@ func (s *SignatureSession) Request(
 		req.Header.Set(key, value)
 	}
 
+	signer = base.BindSignerContext(ctx, signer)
+	var signature string
+	if s.SignatureVersion == "2.0" {
+		signature, err = auth.GenerateRequestSignatureWithSigner(
+			base.NewSignableRequest(req, requestBody), s.SignedHeaders, signer,
+		)
+	} else {
+		signature, err = auth.GenerateMessageSignatureWithSigner(string(requestBody), signer)
+	}
+	if err != nil {
+		return nil, err
+	}
+
 	req.Header.Set(auth.SignatureHeaderName, signature)
 
 	return s.Do(req)
 
 }
 
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	defer c.cancel()
+	return c.ReadCloser.Close()
+}
+
 var SignedClient = newSignatureSession(nil)
@@ -0,0 +1,146 @@
+package infuzu
+
+import (
+	"context"
+	"errors"
+	base "github.com/infuzu/infuzu-go-sdk/infuzu/authentication/base"
+	auth "github.com/infuzu/infuzu-go-sdk/infuzu/authentication/shortcuts"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type verifyingServer struct {
+	*httptest.Server
+	versions chan string
+}
+
+func newVerifyingServer(t *testing.T, publicKey string) *verifyingServer {
+	t.Helper()
+	s := &verifyingServer{versions: make(chan string, 16)}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		signature := r.Header.Get(auth.SignatureHeaderName)
+		parsed, err := base.ParseSignature(signature)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		s.versions <- parsed.Version
+		valid, err := auth.VerifyRequestSignature(base.NewSignableRequest(r, body), signature, publicKey)
+		if err != nil || !valid {
+			http.Error(w, "invalid signature", http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func mustKeyStrings(t *testing.T) (string, string) {
+	t.Helper()
+	keys, err := base.GenerateIKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateKey, err := keys.PrivateKey.ToBase64()
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey, err := keys.PublicKey.ToBase64()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return privateKey, publicKey
+}
+
+func TestSignatureSessionSignsRequestsWithDefaultVersion(t *testing.T) {
+	privateKey, publicKey := mustKeyStrings(t)
+	server := newVerifyingServer(t, publicKey)
+	session := NewSignatureSession(&privateKey)
+
+	resp, err := session.Request("POST", server.URL+"/items/?b=2&a=1", map[string]string{"name": "item"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("signed request was rejected: %s", resp.Status)
+	}
+	if version := <-server.versions; version != DefaultSignatureVersion {
+		t.Fatalf("expected version %s, got %s", DefaultSignatureVersion, version)
+	}
+}
+
+func TestSignatureSessionSignsRequestBoundSignaturesWhenOptedIn(t *testing.T) {
+	privateKey, publicKey := mustKeyStrings(t)
+	server := newVerifyingServer(t, publicKey)
+	session := NewSignatureSession(&privateKey)
+	session.SignatureVersion = "2.0"
+
+	resp, err := session.Request("POST", server.URL+"/items/?b=2&a=1", map[string]string{"name": "item"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("signed request was rejected: %s", resp.Status)
+	}
+	if version := <-server.versions; version != "2.0" {
+		t.Fatalf("expected version 2.0, got %s", version)
+	}
+}
+
+type contextKey struct{}
+
+type recordingSigner struct {
+	*base.IPrivateKey
+	contexts chan context.Context
+}
+
+func (s recordingSigner) SignContext(ctx context.Context, digest []byte) ([]byte, error) {
+	s.contexts <- ctx
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.Sign(digest)
+}
+
+func TestSignatureSessionWithSignerUsesRequestContext(t *testing.T) {
+	keys, err := base.GenerateIKeysWithAlgorithm(base.AlgorithmEd25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey, err := keys.PublicKey.ToBase64()
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := newVerifyingServer(t, publicKey)
+	signer := recordingSigner{IPrivateKey: keys.PrivateKey, contexts: make(chan context.Context, 4)}
+	session := NewSignatureSessionWithSigner(signer)
+
+	ctx := context.WithValue(context.Background(), contextKey{}, "request")
+	resp, err := session.RequestContext(ctx, "POST", server.URL+"/items/", map[string]string{"name": "item"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("request signed by the signer was rejected: %s", resp.Status)
+	}
+	if signedWith := <-signer.contexts; signedWith.Value(contextKey{}) != "request" {
+		t.Fatal("the signer did not receive the request context")
+	}
+	<-server.versions
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err = session.RequestContext(cancelled, "GET", server.URL+"/items/", nil, nil); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected signing to stop with the request, got %v", err)
+	}
+	if len(server.versions) != 0 {
+		t.Fatal("a request whose signing was cancelled reached the server")
+	}
+}
@@ -0,0 +1,36 @@
+package infuzu
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+func (s *SignatureSession) RequestJSON(
+	ctx context.Context, method string, url string, body interface{}, result interface{},
+) (statusCode int, err error) {
+	var resp *http.Response
+	resp, err = s.RequestContext(ctx, method, url, body, map[string]string{"Accept": "application/json"})
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if cerr := resp.Body.Close(); cerr != nil && err == nil {
+			err = fmt.Errorf("failed to close response body: %w", cerr)
+		}
+	}()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return resp.StatusCode, NewAPIError("infuzu/requests/json_requests.go", method, url, resp)
+	}
+
+	if result == nil || resp.StatusCode == http.StatusNoContent {
+		return resp.StatusCode, nil
+	}
+	if err = json.NewDecoder(resp.Body).Decode(result); err != nil && err != io.EOF {
+		return resp.StatusCode, err
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,88 @@
+package infuzu
+
+import (
+	"bytes"
+	"encoding/json"
+	base "github.com/infuzu/infuzu-go-sdk/infuzu/authentication/base"
+	shortcuts "github.com/infuzu/infuzu-go-sdk/infuzu/authentication/shortcuts"
+	requests "github.com/infuzu/infuzu-go-sdk/infuzu/requests"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+)
+
+// Server is a fake Infuzu service. Like the real services it rejects, with
+// 403, every request whose signature does not verify against Keys.
+type Server struct {
+	*httptest.Server
+	Keys       *base.IKeys
+	PrivateKey string
+	publicKey  *base.IPublicKey
+	unsigned   int
+	mutex      sync.Mutex
+}
+
+func NewServer(handler http.Handler) *Server {
+	keys, err := base.GenerateIKeys()
+	if err != nil {
+		panic(err)
+	}
+	return NewServerWithKeys(keys, handler)
+}
+
+func NewServerWithKeys(keys *base.IKeys, handler http.Handler) *Server {
+	privateKey, err := keys.PrivateKey.ToBase64()
+	if err != nil {
+		panic(err)
+	}
+	s := &Server{Keys: keys, PrivateKey: privateKey, publicKey: keys.PublicKey}
+	s.Server = httptest.NewServer(s.requireSignature(handler))
+	return s
+}
+
+// Session signs with the server's key pair.
+func (s *Server) Session() *requests.SignatureSession {
+	return requests.NewSignatureSession(&s.PrivateKey)
+}
+
+func (s *Server) BaseURL() string {
+	return s.URL + "/"
+}
+
+// UnsignedRequests counts requests rejected for a missing or invalid signature.
+func (s *Server) UnsignedRequests() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.unsigned
+}
+
+// The signature is checked without the replay store, so tests that configure
+// one do not see the fake service's own checks.
+func (s *Server) requireSignature(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			WriteJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		valid, err := s.publicKey.VerifyRequestSignature(
+			base.NewSignableRequest(r, body), r.Header.Get(shortcuts.SignatureHeaderName), shortcuts.AllowedTimeDifference,
+		)
+		if err != nil || !valid {
+			s.mutex.Lock()
+			s.unsigned++
+			s.mutex.Unlock()
+			WriteJSON(w, http.StatusForbidden, map[string]string{"error": "Access Denied - Signature is invalid"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func WriteJSON(w http.ResponseWriter, statusCode int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(value)
+}
@@ -0,0 +1,56 @@
+package infuzu
+
+import (
+	"net/http"
+	"sync"
+)
+
+// Stub answers each request URI, path and query, with the JSON response or
+// status set for it, and with 404 otherwise. It counts requests by method and
+// URI, as in "GET /users/user/user-1/".
+type Stub struct {
+	mutex     sync.Mutex
+	hits      map[string]int
+	statuses  map[string]int
+	responses map[string]interface{}
+}
+
+func NewStub() *Stub {
+	return &Stub{hits: map[string]int{}, statuses: map[string]int{}, responses: map[string]interface{}{}}
+}
+
+// Set answers requestURI with response, or with status when it is not zero.
+func (s *Stub) Set(requestURI string, response interface{}, status int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if status != 0 {
+		s.statuses[requestURI] = status
+		return
+	}
+	delete(s.statuses, requestURI)
+	s.responses[requestURI] = response
+}
+
+func (s *Stub) Count(request string) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.hits[request]
+}
+
+func (s *Stub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	requestURI := r.URL.RequestURI()
+	s.hits[r.Method+" "+requestURI]++
+	status, failing := s.statuses[requestURI]
+	response, found := s.responses[requestURI]
+	s.mutex.Unlock()
+
+	switch {
+	case failing:
+		WriteJSON(w, status, map[string]string{"error": http.StatusText(status)})
+	case !found:
+		WriteJSON(w, http.StatusNotFound, map[string]string{"error": "Not found"})
+	default:
+		WriteJSON(w, http.StatusOK, response)
+	}
+}
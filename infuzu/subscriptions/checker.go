@@ -0,0 +1,182 @@
+package infuzu
+
+import (
+	"context"
+	"errors"
+	utils "github.com/infuzu/infuzu-go-sdk/infuzu/utils"
+	"net/http"
+	"time"
+)
+
+var ErrNoActiveSubscription = errors.New("infuzu/subscriptions/checker.go user has no active subscription to the required plans")
+
+const planCatalogKey = "plans"
+
+type freeTrialKey struct {
+	subscriptionID string
+	userID         string
+}
+
+type Entitlement struct {
+	Subscription *UserSubscription
+	FreeTrial    *FreeTrial
+}
+
+func (e *Entitlement) IsFreeTrial() bool {
+	return e != nil && e.Subscription == nil && e.FreeTrial != nil
+}
+
+type Checker struct {
+	Client            *Client
+	userSubscriptions *utils.Cache[string, []UserSubscription]
+	planCatalog       *utils.Cache[string, map[string]string]
+	freeTrials        *utils.Cache[freeTrialKey, *FreeTrial]
+}
+
+func NewChecker(client *Client, expiryTime time.Duration, maxSize int) *Checker {
+	if client == nil {
+		client = DefaultClient
+	}
+	ch := &Checker{Client: client}
+	ch.userSubscriptions = utils.NewCache(
+		func(ctx context.Context, userID string) ([]UserSubscription, error) {
+			userSubscriptions, err := ch.Client.RetrieveUserSubscriptions(ctx, &UserSubscriptionFilter{UserID: userID})
+			if errors.Is(err, ErrNotFound) {
+				return nil, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			owned := userSubscriptions[:0]
+			for _, userSubscription := range userSubscriptions {
+				if userSubscription.UserID == userID {
+					owned = append(owned, userSubscription)
+				}
+			}
+			return owned, nil
+		},
+		expiryTime,
+		maxSize,
+	)
+	ch.planCatalog = utils.NewCache(
+		func(ctx context.Context, _ string) (map[string]string, error) {
+			subscriptions, err := ch.Client.RetrieveSubscriptions(ctx)
+			if err != nil {
+				return nil, err
+			}
+			catalog := make(map[string]string)
+			for _, subscription := range subscriptions {
+				for _, plan := range subscription.Plans {
+					catalog[plan.ID] = subscription.ID
+				}
+			}
+			return catalog, nil
+		},
+		expiryTime,
+		1,
+	)
+	ch.freeTrials = utils.NewCacheWithOptions(
+		func(ctx context.Context, key freeTrialKey) (*FreeTrial, error) {
+			return ch.Client.RetrieveSubscriptionFreeTrial(ctx, key.subscriptionID, key.userID)
+		},
+		utils.CacheOptions[freeTrialKey, *FreeTrial]{
+			ExpiryTime: expiryTime,
+			MaxSize:    maxSize,
+			Negative: utils.NegativePolicy{
+				ExpiryTime: expiryTime,
+				IsNegative: func(err error) bool {
+					return errors.Is(err, ErrNotFound)
+				},
+			},
+		},
+	)
+	return ch
+}
+
+var DefaultChecker = NewChecker(nil, 30*time.Second, 1000)
+
+// UserSubscriptions returns a copy of the cached subscriptions owned by userID;
+// rows the service returns for other users are dropped.
+func (ch *Checker) UserSubscriptions(ctx context.Context, userID string) ([]UserSubscription, error) {
+	userSubscriptions, err := ch.userSubscriptions.Get(ctx, userID)
+	if err != nil || userSubscriptions == nil {
+		return nil, err
+	}
+	copied := make([]UserSubscription, len(userSubscriptions))
+	for i := range userSubscriptions {
+		copied[i] = *userSubscriptions[i].clone()
+	}
+	return copied, nil
+}
+
+func (ch *Checker) ActiveEntitlement(
+	ctx context.Context, userID string, allowedPlanIDs []string, allowFreeTrial bool,
+) (*Entitlement, error) {
+	userSubscriptions, err := ch.UserSubscriptions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	allowed := make(map[string]bool, len(allowedPlanIDs))
+	for _, planID := range allowedPlanIDs {
+		allowed[planID] = true
+	}
+	for i := range userSubscriptions {
+		userSubscription := &userSubscriptions[i]
+		if allowed[userSubscription.SubscriptionPlanID] && userSubscription.IsActiveAt(now) {
+			return &Entitlement{Subscription: userSubscription}, nil
+		}
+	}
+	if !allowFreeTrial {
+		return nil, ErrNoActiveSubscription
+	}
+	var catalog map[string]string
+	catalog, err = ch.planCatalog.Get(ctx, planCatalogKey)
+	if err != nil {
+		return nil, err
+	}
+	checked := make(map[string]bool)
+	for _, planID := range allowedPlanIDs {
+		subscriptionID, exists := catalog[planID]
+		if !exists || checked[subscriptionID] {
+			continue
+		}
+		checked[subscriptionID] = true
+		var freeTrial *FreeTrial
+		freeTrial, err = ch.freeTrials.Get(ctx, freeTrialKey{subscriptionID: subscriptionID, userID: userID})
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if freeTrial.IsActiveAt(now) {
+			return &Entitlement{FreeTrial: freeTrial.clone()}, nil
+		}
+	}
+	return nil, ErrNoActiveSubscription
+}
+
+// EntitlementFailure returns the status and message the integrations respond
+// with after an entitlement check, or 0 when the user is entitled.
+func EntitlementFailure(err error) (int, string) {
+	if errors.Is(err, ErrNoActiveSubscription) {
+		return http.StatusForbidden, "Access Denied - User does not have an active subscription"
+	}
+	if err != nil {
+		return http.StatusServiceUnavailable, "Access Denied - Unable to verify subscription"
+	}
+	return 0, ""
+}
+
+func (ch *Checker) Invalidate(userID string) {
+	ch.userSubscriptions.Remove(userID)
+}
+
+func (ch *Checker) InvalidateFreeTrial(subscriptionID string, userID string) {
+	ch.freeTrials.Remove(freeTrialKey{subscriptionID: subscriptionID, userID: userID})
+}
+
+func (ch *Checker) InvalidatePlans() {
+	ch.planCatalog.Remove(planCatalogKey)
+}
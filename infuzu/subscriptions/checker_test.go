@@ -0,0 +1,198 @@
+package infuzu
+
+import (
+	"context"
+	"errors"
+	requeststest "github.com/infuzu/infuzu-go-sdk/infuzu/requests/requeststest"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func newChecker(t *testing.T) (*requeststest.Stub, *Checker) {
+	t.Helper()
+	service, client := newSubscriptionService(t)
+	return service, NewChecker(client, time.Minute, 100)
+}
+
+const (
+	userSubscriptionsRequest = "/subscriptions/user-subscriptions/?user_id=user-1"
+	catalogRequest           = "/subscriptions/subscriptions/"
+	freeTrialRequest         = "/subscriptions/subscription-free-trial/subscription-1/user-1/"
+)
+
+var catalog = []Subscription{
+	{ID: "subscription-1", Plans: []SubscriptionPlan{{ID: "monthly"}, {ID: "yearly"}}},
+	{ID: "subscription-2", Plans: []SubscriptionPlan{{ID: "other"}}},
+}
+
+func timeAt(offset time.Duration) *time.Time {
+	moment := time.Now().Add(offset).UTC()
+	return &moment
+}
+
+func TestActiveEntitlementFromSubscription(t *testing.T) {
+	service, checker := newChecker(t)
+	service.Set(userSubscriptionsRequest, []UserSubscription{
+		{ID: "expired", UserID: "user-1", SubscriptionPlanID: "monthly", Status: UserSubscriptionStatusActive, CurrentPeriodEnd: timeAt(-time.Hour)},
+		{ID: "canceled", UserID: "user-1", SubscriptionPlanID: "yearly", Status: UserSubscriptionStatusCanceled},
+		{ID: "other", UserID: "user-1", SubscriptionPlanID: "other", Status: UserSubscriptionStatusActive},
+		{ID: "current", UserID: "user-1", SubscriptionPlanID: "yearly", Status: UserSubscriptionStatusTrialing, CurrentPeriodEnd: timeAt(time.Hour)},
+	}, 0)
+
+	entitlement, err := checker.ActiveEntitlement(context.Background(), "user-1", []string{"monthly", "yearly"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entitlement.IsFreeTrial() || entitlement.Subscription.ID != "current" {
+		t.Fatalf("unexpected entitlement %+v", entitlement)
+	}
+	if _, err = checker.ActiveEntitlement(context.Background(), "user-1", []string{"monthly"}, false); !errors.Is(err, ErrNoActiveSubscription) {
+		t.Fatalf("expected ErrNoActiveSubscription, got %v", err)
+	}
+	if hits := service.Count("GET " + userSubscriptionsRequest); hits != 1 {
+		t.Fatalf("user subscriptions were fetched %d times", hits)
+	}
+	if hits := service.Count("GET " + catalogRequest); hits != 0 {
+		t.Fatalf("plan catalog was fetched %d times without free trials allowed", hits)
+	}
+
+	service.Set(userSubscriptionsRequest, []UserSubscription{}, 0)
+	checker.Invalidate("user-1")
+	if _, err = checker.ActiveEntitlement(context.Background(), "user-1", []string{"yearly"}, false); !errors.Is(err, ErrNoActiveSubscription) {
+		t.Fatalf("expected ErrNoActiveSubscription after invalidation, got %v", err)
+	}
+}
+
+func TestActiveEntitlementFromFreeTrial(t *testing.T) {
+	service, checker := newChecker(t)
+	service.Set(catalogRequest, catalog, 0)
+	service.Set(freeTrialRequest, FreeTrial{
+		ID: "trial", SubscriptionID: "subscription-1", UserID: "user-1", StartTime: timeAt(-time.Hour), EndTime: timeAt(time.Hour),
+	}, 0)
+
+	for i := 0; i < 2; i++ {
+		entitlement, err := checker.ActiveEntitlement(context.Background(), "user-1", []string{"monthly", "yearly"}, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !entitlement.IsFreeTrial() || entitlement.FreeTrial.ID != "trial" {
+			t.Fatalf("unexpected entitlement %+v", entitlement)
+		}
+	}
+	if hits := service.Count("GET " + catalogRequest); hits != 1 {
+		t.Fatalf("plan catalog was fetched %d times", hits)
+	}
+	if hits := service.Count("GET " + freeTrialRequest); hits != 1 {
+		t.Fatalf("free trial was fetched %d times", hits)
+	}
+
+	if _, err := checker.ActiveEntitlement(context.Background(), "user-1", []string{"monthly"}, false); !errors.Is(err, ErrNoActiveSubscription) {
+		t.Fatalf("free trial was used when not allowed: %v", err)
+	}
+	if _, err := checker.ActiveEntitlement(context.Background(), "user-1", []string{"unknown"}, true); !errors.Is(err, ErrNoActiveSubscription) {
+		t.Fatalf("plan outside the catalog was entitled: %v", err)
+	}
+
+	service.Set(freeTrialRequest, FreeTrial{
+		ID: "trial", SubscriptionID: "subscription-1", UserID: "user-1", EndTime: timeAt(-time.Minute),
+	}, 0)
+	checker.InvalidateFreeTrial("subscription-1", "user-1")
+	if _, err := checker.ActiveEntitlement(context.Background(), "user-1", []string{"monthly"}, true); !errors.Is(err, ErrNoActiveSubscription) {
+		t.Fatalf("expired free trial was entitled: %v", err)
+	}
+}
+
+func TestActiveEntitlementCachesMissingFreeTrials(t *testing.T) {
+	service, checker := newChecker(t)
+	service.Set(catalogRequest, catalog, 0)
+
+	for i := 0; i < 2; i++ {
+		if _, err := checker.ActiveEntitlement(context.Background(), "user-1", []string{"monthly"}, true); !errors.Is(err, ErrNoActiveSubscription) {
+			t.Fatalf("expected ErrNoActiveSubscription, got %v", err)
+		}
+	}
+	if hits := service.Count("GET " + freeTrialRequest); hits != 1 {
+		t.Fatalf("missing free trial was fetched %d times", hits)
+	}
+}
+
+func TestActiveEntitlementRefreshesPlanCatalog(t *testing.T) {
+	service, checker := newChecker(t)
+	service.Set(catalogRequest, catalog, 0)
+	service.Set(freeTrialRequest, FreeTrial{ID: "trial", EndTime: timeAt(time.Hour)}, 0)
+
+	if _, err := checker.ActiveEntitlement(context.Background(), "user-1", []string{"weekly"}, true); !errors.Is(err, ErrNoActiveSubscription) {
+		t.Fatalf("expected ErrNoActiveSubscription, got %v", err)
+	}
+	service.Set(catalogRequest, []Subscription{
+		{ID: "subscription-1", Plans: []SubscriptionPlan{{ID: "monthly"}, {ID: "weekly"}}},
+	}, 0)
+	checker.InvalidatePlans()
+	entitlement, err := checker.ActiveEntitlement(context.Background(), "user-1", []string{"weekly"}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !entitlement.IsFreeTrial() {
+		t.Fatalf("unexpected entitlement %+v", entitlement)
+	}
+	if hits := service.Count("GET " + catalogRequest); hits != 2 {
+		t.Fatalf("plan catalog was fetched %d times", hits)
+	}
+}
+
+func TestActiveEntitlementReportsOutages(t *testing.T) {
+	service, checker := newChecker(t)
+	service.Set(catalogRequest, catalog, 0)
+	service.Set(freeTrialRequest, nil, http.StatusServiceUnavailable)
+
+	_, err := checker.ActiveEntitlement(context.Background(), "user-1", []string{"monthly"}, true)
+	if err == nil || errors.Is(err, ErrNoActiveSubscription) || errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected the outage to be reported, got %v", err)
+	}
+	service.Set(freeTrialRequest, FreeTrial{ID: "trial", EndTime: timeAt(time.Hour)}, 0)
+	if _, err = checker.ActiveEntitlement(context.Background(), "user-1", []string{"monthly"}, true); err != nil {
+		t.Fatalf("outage was cached: %v", err)
+	}
+
+	service.Set("/subscriptions/user-subscriptions/?user_id=user-2", nil, http.StatusInternalServerError)
+	if _, err = checker.ActiveEntitlement(context.Background(), "user-2", []string{"monthly"}, true); err == nil {
+		t.Fatal("expected the user subscription outage to be reported")
+	}
+}
+
+func TestActiveEntitlementIgnoresOtherUsersSubscriptions(t *testing.T) {
+	service, checker := newChecker(t)
+	service.Set(userSubscriptionsRequest, []UserSubscription{
+		{ID: "foreign", UserID: "user-2", SubscriptionPlanID: "monthly", Status: UserSubscriptionStatusActive},
+	}, 0)
+
+	if _, err := checker.ActiveEntitlement(context.Background(), "user-1", []string{"monthly"}, false); !errors.Is(err, ErrNoActiveSubscription) {
+		t.Fatalf("another user's subscription was entitled: %v", err)
+	}
+	if userSubscriptions, err := checker.UserSubscriptions(context.Background(), "user-1"); err != nil || len(userSubscriptions) != 0 {
+		t.Fatalf("expected no subscriptions, got %+v, %v", userSubscriptions, err)
+	}
+}
+
+func TestCheckerReturnsCopies(t *testing.T) {
+	service, checker := newChecker(t)
+	service.Set(userSubscriptionsRequest, []UserSubscription{
+		{ID: "current", UserID: "user-1", SubscriptionPlanID: "monthly", Status: UserSubscriptionStatusActive, CurrentPeriodEnd: timeAt(time.Hour)},
+	}, 0)
+	ctx := context.Background()
+
+	userSubscriptions, err := checker.UserSubscriptions(ctx, "user-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	userSubscriptions[0].Status = UserSubscriptionStatusCanceled
+	entitlement, err := checker.ActiveEntitlement(ctx, "user-1", []string{"monthly"}, false)
+	if err != nil {
+		t.Fatalf("mutating a returned slice changed the cache: %v", err)
+	}
+	*entitlement.Subscription.CurrentPeriodEnd = time.Now().Add(-time.Hour)
+	if _, err = checker.ActiveEntitlement(ctx, "user-1", []string{"monthly"}, false); err != nil {
+		t.Fatalf("mutating a returned entitlement changed the cache: %v", err)
+	}
+}
@@ -0,0 +1,355 @@
+package infuzu
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	constants "github.com/infuzu/infuzu-go-sdk/infuzu/constants"
+	requests "github.com/infuzu/infuzu-go-sdk/infuzu/requests"
+	utils "github.com/infuzu/infuzu-go-sdk/infuzu/utils"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+var ErrNotFound = errors.New("infuzu/subscriptions/subscriptions.go subscription resource not found")
+
+const OverviewTimeFormat = "2006-01-02T15:04:05Z"
+
+type SubscriptionPlan struct {
+	ID             string  `json:"id"`
+	Name           string  `json:"name"`
+	Price          float64 `json:"price"`
+	Currency       string  `json:"currency,omitempty"`
+	Interval       string  `json:"interval,omitempty"`
+	IntervalCount  int     `json:"interval_count,omitempty"`
+	FreeTrialDays  int     `json:"free_trial_days,omitempty"`
+	SubscriptionID string  `json:"subscription_id,omitempty"`
+}
+
+type Subscription struct {
+	ID          string             `json:"id"`
+	Name        string             `json:"name"`
+	Description string             `json:"description,omitempty"`
+	IsActive    bool               `json:"is_active"`
+	Plans       []SubscriptionPlan `json:"plans,omitempty"`
+}
+
+type SubscriptionOverview struct {
+	StartTime              time.Time `json:"start_time"`
+	EndTime                time.Time `json:"end_time"`
+	ActiveSubscriptions    int       `json:"active_subscriptions"`
+	NewSubscriptions       int       `json:"new_subscriptions"`
+	CancelledSubscriptions int       `json:"cancelled_subscriptions"`
+	ActiveFreeTrials       int       `json:"active_free_trials"`
+	Revenue                float64   `json:"revenue"`
+}
+
+type FreeTrial struct {
+	ID             string     `json:"id,omitempty"`
+	SubscriptionID string     `json:"subscription_id"`
+	UserID         string     `json:"user_id"`
+	StartTime      *time.Time `json:"start_time,omitempty"`
+	EndTime        *time.Time `json:"end_time,omitempty"`
+}
+
+func (ft *FreeTrial) clone() *FreeTrial {
+	cloned := *ft
+	cloned.StartTime = cloneTime(ft.StartTime)
+	cloned.EndTime = cloneTime(ft.EndTime)
+	return &cloned
+}
+
+func (ft *FreeTrial) IsActiveAt(moment time.Time) bool {
+	if ft == nil || ft.EndTime == nil {
+		return false
+	}
+	if ft.StartTime != nil && moment.Before(*ft.StartTime) {
+		return false
+	}
+	return moment.Before(*ft.EndTime)
+}
+
+const (
+	UserSubscriptionStatusActive   = "active"
+	UserSubscriptionStatusTrialing = "trialing"
+	UserSubscriptionStatusPastDue  = "past_due"
+	UserSubscriptionStatusCanceled = "canceled"
+)
+
+type UserSubscription struct {
+	ID                 string     `json:"id,omitempty"`
+	UserID             string     `json:"user_id"`
+	SubscriptionID     string     `json:"subscription_id,omitempty"`
+	SubscriptionPlanID string     `json:"subscription_plan_id"`
+	Status             string     `json:"status,omitempty"`
+	StartTime          *time.Time `json:"start_time,omitempty"`
+	CurrentPeriodEnd   *time.Time `json:"current_period_end,omitempty"`
+	CancelAtPeriodEnd  bool       `json:"cancel_at_period_end,omitempty"`
+}
+
+func (us *UserSubscription) clone() *UserSubscription {
+	cloned := *us
+	cloned.StartTime = cloneTime(us.StartTime)
+	cloned.CurrentPeriodEnd = cloneTime(us.CurrentPeriodEnd)
+	return &cloned
+}
+
+func (us *UserSubscription) IsActiveAt(moment time.Time) bool {
+	if us == nil {
+		return false
+	}
+	if us.Status != UserSubscriptionStatusActive && us.Status != UserSubscriptionStatusTrialing {
+		return false
+	}
+	if us.StartTime != nil && moment.Before(*us.StartTime) {
+		return false
+	}
+	return us.CurrentPeriodEnd == nil || moment.Before(*us.CurrentPeriodEnd)
+}
+
+func (us *UserSubscription) IsTrialing() bool {
+	return us != nil && us.Status == UserSubscriptionStatusTrialing
+}
+
+func cloneTime(moment *time.Time) *time.Time {
+	if moment == nil {
+		return nil
+	}
+	cloned := *moment
+	return &cloned
+}
+
+type UserSubscriptionFilter struct {
+	UserID             string
+	SubscriptionID     string
+	SubscriptionPlanID string
+	Status             string
+}
+
+func (f *UserSubscriptionFilter) query() string {
+	if f == nil {
+		return ""
+	}
+	values := url.Values{}
+	if f.UserID != "" {
+		values.Set("user_id", f.UserID)
+	}
+	if f.SubscriptionID != "" {
+		values.Set("subscription_id", f.SubscriptionID)
+	}
+	if f.SubscriptionPlanID != "" {
+		values.Set("subscription_plan_id", f.SubscriptionPlanID)
+	}
+	if f.Status != "" {
+		values.Set("status", f.Status)
+	}
+	if len(values) == 0 {
+		return ""
+	}
+	return "?" + values.Encode()
+}
+
+type Client struct {
+	Session *requests.SignatureSession
+	BaseURL string
+}
+
+func NewClient(session *requests.SignatureSession) *Client {
+	if session == nil {
+		session = requests.SignedClient
+	}
+	return &Client{Session: session}
+}
+
+var DefaultClient = NewClient(nil)
+
+func (c *Client) baseURL() string {
+	if c.BaseURL != "" {
+		return c.BaseURL
+	}
+	return constants.SubscriptionsBaseURL()
+}
+
+func (c *Client) RetrieveSubscriptions(ctx context.Context) ([]Subscription, error) {
+	var subscriptions []Subscription
+	err := c.do(ctx, "GET", constants.SubscriptionsRetrieveSubscriptions(), nil, "", nil, &subscriptions)
+	if err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+func (c *Client) RetrieveSubscription(ctx context.Context, subscriptionID string) (*Subscription, error) {
+	var subscription Subscription
+	err := c.do(
+		ctx, "GET", constants.SubscriptionsRetrieveSubscription(),
+		map[string]interface{}{"subscription_id": subscriptionID}, "", nil, &subscription,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+func (c *Client) RetrieveSubscriptionOverview(
+	ctx context.Context, startTime time.Time, endTime time.Time,
+) (*SubscriptionOverview, error) {
+	if endTime.Before(startTime) {
+		return nil, errors.New("infuzu/subscriptions/subscriptions.go overview end time is before start time")
+	}
+	var overview SubscriptionOverview
+	err := c.do(
+		ctx, "GET", constants.SubscriptionsRetrieveSubscriptionOverview(),
+		map[string]interface{}{
+			"start_time": startTime.UTC().Format(OverviewTimeFormat),
+			"end_time":   endTime.UTC().Format(OverviewTimeFormat),
+		},
+		"", nil, &overview,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &overview, nil
+}
+
+func (c *Client) RetrieveSubscriptionFreeTrial(
+	ctx context.Context, subscriptionID string, userID string,
+) (*FreeTrial, error) {
+	var freeTrial FreeTrial
+	err := c.do(
+		ctx, "GET", constants.SubscriptionsRetrieveSubscriptionFreeTrial(),
+		map[string]interface{}{"subscription_id": subscriptionID, "user_id": userID}, "", nil, &freeTrial,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &freeTrial, nil
+}
+
+func (c *Client) CreateSubscriptionFreeTrial(
+	ctx context.Context, subscriptionID string, userID string,
+) (*FreeTrial, error) {
+	var freeTrial FreeTrial
+	err := c.do(
+		ctx, "POST", constants.SubscriptionsCreateSubscriptionFreeTrial(),
+		map[string]interface{}{"subscription_id": subscriptionID, "user_id": userID}, "", nil, &freeTrial,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &freeTrial, nil
+}
+
+func (c *Client) SubscribeToPlan(
+	ctx context.Context, subscriptionPlanID string, userID string,
+) (*UserSubscription, error) {
+	var userSubscription UserSubscription
+	err := c.do(
+		ctx, "POST", constants.SubscriptionsSubscribeToPlan(),
+		map[string]interface{}{"subscription_plan_id": subscriptionPlanID, "user_id": userID},
+		"", nil, &userSubscription,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &userSubscription, nil
+}
+
+func (c *Client) RetrieveUserSubscriptions(
+	ctx context.Context, filter *UserSubscriptionFilter,
+) ([]UserSubscription, error) {
+	var userSubscriptions []UserSubscription
+	err := c.do(
+		ctx, "GET", constants.SubscriptionsRetrieveUserSubscriptions(), nil, filter.query(), nil, &userSubscriptions,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return userSubscriptions, nil
+}
+
+func (c *Client) CreateUserSubscription(
+	ctx context.Context, userSubscription *UserSubscription,
+) (*UserSubscription, error) {
+	if userSubscription == nil {
+		return nil, errors.New("infuzu/subscriptions/subscriptions.go user subscription cannot be nil")
+	}
+	var created UserSubscription
+	err := c.do(
+		ctx, "POST", constants.SubscriptionsCreateUserSubscription(), nil, "", userSubscription, &created,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+func (c *Client) RetrieveUserSubscription(ctx context.Context, userSubscriptionID string) (*UserSubscription, error) {
+	var userSubscription UserSubscription
+	err := c.do(
+		ctx, "GET", constants.SubscriptionsRetrieveUserSubscription(),
+		map[string]interface{}{"user_subscription_id": userSubscriptionID}, "", nil, &userSubscription,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &userSubscription, nil
+}
+
+func (c *Client) do(
+	ctx context.Context,
+	method string,
+	endpoint string,
+	params map[string]interface{},
+	query string,
+	body interface{},
+	result interface{},
+) error {
+	url, err := utils.BuildURL(c.baseURL(), endpoint, params)
+	if err != nil {
+		return err
+	}
+	var statusCode int
+	statusCode, err = c.Session.RequestJSON(ctx, method, url+query, body, result)
+	if statusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	}
+	return err
+}
+
+func RetrieveSubscriptions(ctx context.Context) ([]Subscription, error) {
+	return DefaultClient.RetrieveSubscriptions(ctx)
+}
+
+func RetrieveSubscription(ctx context.Context, subscriptionID string) (*Subscription, error) {
+	return DefaultClient.RetrieveSubscription(ctx, subscriptionID)
+}
+
+func RetrieveSubscriptionOverview(ctx context.Context, startTime time.Time, endTime time.Time) (*SubscriptionOverview, error) {
+	return DefaultClient.RetrieveSubscriptionOverview(ctx, startTime, endTime)
+}
+
+func RetrieveSubscriptionFreeTrial(ctx context.Context, subscriptionID string, userID string) (*FreeTrial, error) {
+	return DefaultClient.RetrieveSubscriptionFreeTrial(ctx, subscriptionID, userID)
+}
+
+func CreateSubscriptionFreeTrial(ctx context.Context, subscriptionID string, userID string) (*FreeTrial, error) {
+	return DefaultClient.CreateSubscriptionFreeTrial(ctx, subscriptionID, userID)
+}
+
+func SubscribeToPlan(ctx context.Context, subscriptionPlanID string, userID string) (*UserSubscription, error) {
+	return DefaultClient.SubscribeToPlan(ctx, subscriptionPlanID, userID)
+}
+
+func RetrieveUserSubscriptions(ctx context.Context, filter *UserSubscriptionFilter) ([]UserSubscription, error) {
+	return DefaultClient.RetrieveUserSubscriptions(ctx, filter)
+}
+
+func CreateUserSubscription(ctx context.Context, userSubscription *UserSubscription) (*UserSubscription, error) {
+	return DefaultClient.CreateUserSubscription(ctx, userSubscription)
+}
+
+func RetrieveUserSubscription(ctx context.Context, userSubscriptionID string) (*UserSubscription, error) {
+	return DefaultClient.RetrieveUserSubscription(ctx, userSubscriptionID)
+}
@@ -0,0 +1,83 @@
+package infuzu
+
+import (
+	"context"
+	"errors"
+	requeststest "github.com/infuzu/infuzu-go-sdk/infuzu/requests/requeststest"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func newSubscriptionService(t *testing.T) (*requeststest.Stub, *Client) {
+	t.Helper()
+	stub := requeststest.NewStub()
+	server := requeststest.NewServer(stub)
+	t.Cleanup(server.Close)
+	client := NewClient(server.Session())
+	client.BaseURL = server.BaseURL()
+	return stub, client
+}
+
+func TestRetrieveSubscriptionOverviewFormatsTimes(t *testing.T) {
+	service, client := newSubscriptionService(t)
+	service.Set(
+		"/subscriptions/overview/2024-03-01T12:00:00Z/2024-03-31T23:59:59Z/",
+		SubscriptionOverview{ActiveSubscriptions: 7}, 0,
+	)
+
+	start := time.Date(2024, 3, 1, 13, 0, 0, 0, time.FixedZone("CET", 3600))
+	end := time.Date(2024, 3, 31, 23, 59, 59, 0, time.UTC)
+	overview, err := client.RetrieveSubscriptionOverview(context.Background(), start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if overview.ActiveSubscriptions != 7 {
+		t.Fatalf("unexpected overview %+v", overview)
+	}
+	if _, err = client.RetrieveSubscriptionOverview(context.Background(), end, start); err == nil {
+		t.Fatal("expected an error for an inverted time range")
+	}
+}
+
+func TestRetrieveUserSubscriptionsFilter(t *testing.T) {
+	service, client := newSubscriptionService(t)
+	service.Set(
+		"/subscriptions/user-subscriptions/?status=active&subscription_plan_id=monthly&user_id=user-1",
+		[]UserSubscription{{ID: "current"}}, 0,
+	)
+	service.Set("/subscriptions/user-subscriptions/", []UserSubscription{{ID: "a"}, {ID: "b"}}, 0)
+
+	filtered, err := client.RetrieveUserSubscriptions(context.Background(), &UserSubscriptionFilter{
+		UserID: "user-1", SubscriptionPlanID: "monthly", Status: UserSubscriptionStatusActive,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filtered) != 1 || filtered[0].ID != "current" {
+		t.Fatalf("unexpected user subscriptions %+v", filtered)
+	}
+	all, err := client.RetrieveUserSubscriptions(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 2 {
+		t.Fatalf("unexpected user subscriptions %+v", all)
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	service, client := newSubscriptionService(t)
+	service.Set("/subscriptions/subscription/broken/", nil, http.StatusInternalServerError)
+
+	if _, err := client.RetrieveSubscription(context.Background(), "missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	_, err := client.RetrieveSubscription(context.Background(), "broken")
+	if err == nil || errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected a server error, got %v", err)
+	}
+	if _, err = client.CreateUserSubscription(context.Background(), nil); err == nil {
+		t.Fatal("expected an error for a nil user subscription")
+	}
+}
@@ -0,0 +1,124 @@
+package infuzu
+
+import (
+	"context"
+	"errors"
+	utils "github.com/infuzu/infuzu-go-sdk/infuzu/utils"
+	"sync"
+	"time"
+)
+
+const DefaultLookupConcurrency = 8
+
+type Directory struct {
+	Client      *Client
+	Concurrency int
+	users       *utils.Cache[string, *User]
+}
+
+func NewDirectory(client *Client, expiryTime time.Duration, maxSize int) *Directory {
+	if client == nil {
+		client = DefaultClient
+	}
+	d := &Directory{Client: client, Concurrency: DefaultLookupConcurrency}
+	d.users = utils.NewCacheWithOptions(
+		func(ctx context.Context, userID string) (*User, error) {
+			return d.Client.RetrieveUser(ctx, userID)
+		},
+		utils.CacheOptions[string, *User]{
+			ExpiryTime: expiryTime,
+			MaxSize:    maxSize,
+			Negative: utils.NegativePolicy{
+				ExpiryTime: expiryTime,
+				IsNegative: func(err error) bool {
+					return errors.Is(err, ErrNotFound)
+				},
+			},
+		},
+	)
+	return d
+}
+
+var DefaultDirectory = NewDirectory(nil, 5*time.Minute, 10000)
+
+func (d *Directory) GetUser(ctx context.Context, userID string) (*User, error) {
+	return d.users.Get(ctx, userID)
+}
+
+func (d *Directory) GetUsers(ctx context.Context, userIDs []string) (map[string]*User, error) {
+	users := make(map[string]*User, len(userIDs))
+	var pending []string
+	for _, userID := range userIDs {
+		if _, seen := users[userID]; seen || userID == "" {
+			continue
+		}
+		if user, cached := d.users.Peek(userID); cached {
+			users[userID] = user
+			continue
+		}
+		users[userID] = nil
+		pending = append(pending, userID)
+	}
+
+	concurrency := d.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		mutex    sync.Mutex
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	slots := make(chan struct{}, concurrency)
+	for _, userID := range pending {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(userID string) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			user, err := d.users.Get(ctx, userID)
+			mutex.Lock()
+			defer mutex.Unlock()
+			if err == nil {
+				users[userID] = user
+			} else if !errors.Is(err, ErrNotFound) && firstErr == nil {
+				firstErr = err
+				cancel()
+			}
+		}(userID)
+	}
+	wg.Wait()
+
+	if firstErr == nil {
+		firstErr = ctx.Err()
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	for userID, user := range users {
+		if user == nil {
+			delete(users, userID)
+		}
+	}
+	return users, nil
+}
+
+func (d *Directory) Invalidate(userID string) {
+	d.users.Remove(userID)
+}
+
+func GetUser(ctx context.Context, userID string) (*User, error) {
+	return DefaultDirectory.GetUser(ctx, userID)
+}
+
+func GetUsers(ctx context.Context, userIDs []string) (map[string]*User, error) {
+	return DefaultDirectory.GetUsers(ctx, userIDs)
+}
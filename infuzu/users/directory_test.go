@@ -0,0 +1,179 @@
+package infuzu
+
+import (
+	"context"
+	"errors"
+	requeststest "github.com/infuzu/infuzu-go-sdk/infuzu/requests/requeststest"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type userService struct {
+	*requeststest.Server
+	mutex       sync.Mutex
+	hits        map[string]int
+	failures    map[string]int
+	delay       time.Duration
+	inFlight    int
+	maxInFlight int
+}
+
+func newUserService(t *testing.T) *userService {
+	t.Helper()
+	s := &userService{hits: map[string]int{}, failures: map[string]int{}}
+	s.Server = requeststest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/users/user/"), "/")
+		s.mutex.Lock()
+		s.hits[userID]++
+		s.inFlight++
+		if s.inFlight > s.maxInFlight {
+			s.maxInFlight = s.inFlight
+		}
+		status, failing := s.failures[userID]
+		delay := s.delay
+		s.mutex.Unlock()
+		defer func() {
+			s.mutex.Lock()
+			defer s.mutex.Unlock()
+			s.inFlight--
+		}()
+
+		time.Sleep(delay)
+		if failing {
+			http.Error(w, http.StatusText(status), status)
+			return
+		}
+		if strings.HasPrefix(userID, "missing") {
+			http.NotFound(w, r)
+			return
+		}
+		requeststest.WriteJSON(w, http.StatusOK, User{ID: userID, Username: "name-" + userID})
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *userService) count(userID string) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.hits[userID]
+}
+
+func (s *userService) directory() *Directory {
+	client := NewClient(s.Session())
+	client.BaseURL = s.BaseURL()
+	return NewDirectory(client, time.Minute, 100)
+}
+
+func TestGetUserCachesUsers(t *testing.T) {
+	service := newUserService(t)
+	directory := service.directory()
+
+	for i := 0; i < 2; i++ {
+		user, err := directory.GetUser(context.Background(), "user-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if user.ID != "user-1" || user.DisplayName() != "name-user-1" {
+			t.Fatalf("unexpected user %+v", user)
+		}
+		if _, err = directory.GetUser(context.Background(), "missing-1"); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("expected ErrNotFound, got %v", err)
+		}
+	}
+	if service.count("user-1") != 1 || service.count("missing-1") != 1 {
+		t.Fatalf("users were fetched %d and %d times", service.count("user-1"), service.count("missing-1"))
+	}
+
+	directory.Invalidate("user-1")
+	if _, err := directory.GetUser(context.Background(), "user-1"); err != nil {
+		t.Fatal(err)
+	}
+	if hits := service.count("user-1"); hits != 2 {
+		t.Fatalf("invalidated user was fetched %d times", hits)
+	}
+}
+
+func TestGetUsersDeduplicates(t *testing.T) {
+	service := newUserService(t)
+	directory := service.directory()
+	if _, err := directory.GetUser(context.Background(), "cached"); err != nil {
+		t.Fatal(err)
+	}
+
+	users, err := directory.GetUsers(
+		context.Background(), []string{"user-1", "user-2", "user-1", "", "cached", "missing-1", "user-2"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 3 || users["user-1"] == nil || users["user-2"] == nil || users["cached"] == nil {
+		t.Fatalf("unexpected users %+v", users)
+	}
+	if _, found := users["missing-1"]; found {
+		t.Fatal("missing user was included in the result")
+	}
+	for userID, expected := range map[string]int{"user-1": 1, "user-2": 1, "cached": 1, "missing-1": 1, "": 0} {
+		if hits := service.count(userID); hits != expected {
+			t.Fatalf("%q was fetched %d times, expected %d", userID, hits, expected)
+		}
+	}
+
+	if _, err = directory.GetUsers(context.Background(), []string{"user-1", "user-2", "missing-1"}); err != nil {
+		t.Fatal(err)
+	}
+	if service.count("user-1") != 1 || service.count("missing-1") != 1 {
+		t.Fatal("cached users were fetched again")
+	}
+}
+
+func TestGetUsersBoundsConcurrency(t *testing.T) {
+	service := newUserService(t)
+	service.delay = 10 * time.Millisecond
+	directory := service.directory()
+	directory.Concurrency = 3
+
+	userIDs := make([]string, 12)
+	for i := range userIDs {
+		userIDs[i] = "user-" + strconv.Itoa(i)
+	}
+	users, err := directory.GetUsers(context.Background(), userIDs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != len(userIDs) {
+		t.Fatalf("got %d users", len(users))
+	}
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+	if service.maxInFlight < 2 || service.maxInFlight > 3 {
+		t.Fatalf("%d lookups ran at once, expected at most 3 in parallel", service.maxInFlight)
+	}
+}
+
+func TestGetUsersAbortsOnFirstError(t *testing.T) {
+	service := newUserService(t)
+	service.failures["broken"] = http.StatusInternalServerError
+	directory := service.directory()
+	directory.Concurrency = 1
+
+	users, err := directory.GetUsers(context.Background(), []string{"broken", "user-1", "user-2", "user-3"})
+	if err == nil || errors.Is(err, ErrNotFound) || users != nil {
+		t.Fatalf("expected the failure to be returned, got %v and %+v", err, users)
+	}
+	for _, userID := range []string{"user-1", "user-2", "user-3"} {
+		if hits := service.count(userID); hits != 0 {
+			t.Fatalf("%s was fetched after the batch failed", userID)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err = directory.GetUsers(ctx, []string{"user-4"}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
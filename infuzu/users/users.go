@@ -0,0 +1,91 @@
+package infuzu
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	constants "github.com/infuzu/infuzu-go-sdk/infuzu/constants"
+	requests "github.com/infuzu/infuzu-go-sdk/infuzu/requests"
+	utils "github.com/infuzu/infuzu-go-sdk/infuzu/utils"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var ErrNotFound = errors.New("infuzu/users/users.go user not found")
+
+type User struct {
+	ID             string     `json:"id"`
+	Username       string     `json:"username,omitempty"`
+	Email          string     `json:"email,omitempty"`
+	FirstName      string     `json:"first_name,omitempty"`
+	LastName       string     `json:"last_name,omitempty"`
+	ProfilePicture string     `json:"profile_picture,omitempty"`
+	IsActive       bool       `json:"is_active"`
+	DateJoined     *time.Time `json:"date_joined,omitempty"`
+}
+
+func (u *User) FullName() string {
+	if u == nil {
+		return ""
+	}
+	return strings.TrimSpace(u.FirstName + " " + u.LastName)
+}
+
+func (u *User) DisplayName() string {
+	if fullName := u.FullName(); fullName != "" {
+		return fullName
+	}
+	if u == nil {
+		return ""
+	}
+	if u.Username != "" {
+		return u.Username
+	}
+	return u.Email
+}
+
+type Client struct {
+	Session *requests.SignatureSession
+	BaseURL string
+}
+
+func NewClient(session *requests.SignatureSession) *Client {
+	if session == nil {
+		session = requests.SignedClient
+	}
+	return &Client{Session: session}
+}
+
+var DefaultClient = NewClient(nil)
+
+func (c *Client) baseURL() string {
+	if c.BaseURL != "" {
+		return c.BaseURL
+	}
+	return constants.UsersBaseURL()
+}
+
+func (c *Client) RetrieveUser(ctx context.Context, userID string) (*User, error) {
+	if userID == "" {
+		return nil, errors.New("infuzu/users/users.go user id cannot be empty")
+	}
+	url, err := utils.BuildURL(c.baseURL(), constants.UsersRetrieveUserEndpoint(), map[string]interface{}{"user_id": userID})
+	if err != nil {
+		return nil, err
+	}
+	var user User
+	var statusCode int
+	statusCode, err = c.Session.RequestJSON(ctx, "GET", url, nil, &user)
+	if statusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %w", ErrNotFound, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func RetrieveUser(ctx context.Context, userID string) (*User, error) {
+	return DefaultClient.RetrieveUser(ctx, userID)
+}
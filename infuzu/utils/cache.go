@@ -0,0 +1,485 @@
+package infuzu
+
+import (
+	"container/heap"
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type Loader[K comparable, V any] func(ctx context.Context, key K) (V, error)
+
+type EvictionCallback[K comparable, V any] func(key K, value V, reason EvictionReason)
+
+type StalePolicy struct {
+	MaxStale               time.Duration
+	RevalidateInBackground bool
+	ServeOnError           bool
+}
+
+type NegativePolicy struct {
+	ExpiryTime time.Duration
+	MaxSize    int
+	IsNegative func(err error) bool
+}
+
+type CacheOptions[K comparable, V any] struct {
+	ExpiryTime     time.Duration
+	MaxSize        int
+	EvictionPolicy EvictionPolicy
+	OnEviction     EvictionCallback[K, V]
+	Stale          StalePolicy
+	Negative       NegativePolicy
+	LoadTimeout    time.Duration
+}
+
+type cacheItem[K comparable, V any] struct {
+	key        K
+	data       V
+	expiryTime time.Time
+	heapIndex  int
+	element    *list.Element
+	bucket     *list.Element
+}
+
+type evictedItem[K comparable, V any] struct {
+	key    K
+	data   V
+	reason EvictionReason
+}
+
+type inflightCall[V any] struct {
+	done        chan struct{}
+	run         func(ctx context.Context)
+	data        V
+	err         error
+	invalidated bool
+}
+
+func (call *inflightCall[V]) wait(ctx context.Context) (V, error) {
+	select {
+	case <-call.done:
+		return call.data, call.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+type Cache[K comparable, V any] struct {
+	entries           map[K]*cacheItem[K, V]
+	calls             map[K]*inflightCall[V]
+	expiries          expiryHeap[K, V]
+	index             evictionIndex[K, V]
+	defaultLoader     Loader[K, V]
+	defaultExpiryTime time.Duration
+	maxSize           int
+	policy            EvictionPolicy
+	onEviction        EvictionCallback[K, V]
+	stale             StalePolicy
+	negative          NegativePolicy
+	negatives         *Cache[K, error]
+	loadTimeout       time.Duration
+	hits              int
+	misses            int
+	evictions         int
+	expirations       int
+	coalesced         int
+	staleHits         int
+	staleOnError      int
+	refreshes         int
+	refreshErrors     int
+	negativeHits      int
+	mutex             sync.Mutex
+}
+
+func NewCache[K comparable, V any](
+	defaultLoader Loader[K, V], defaultExpiryTime time.Duration, maxSize int,
+) *Cache[K, V] {
+	return NewCacheWithOptions(defaultLoader, CacheOptions[K, V]{
+		ExpiryTime: defaultExpiryTime,
+		MaxSize:    maxSize,
+	})
+}
+
+func NewCacheWithOptions[K comparable, V any](defaultLoader Loader[K, V], options CacheOptions[K, V]) *Cache[K, V] {
+	c := &Cache[K, V]{
+		entries:           make(map[K]*cacheItem[K, V]),
+		calls:             make(map[K]*inflightCall[V]),
+		defaultLoader:     defaultLoader,
+		defaultExpiryTime: options.ExpiryTime,
+		maxSize:           options.MaxSize,
+		policy:            options.EvictionPolicy,
+		onEviction:        options.OnEviction,
+		stale:             options.Stale,
+		loadTimeout:       options.LoadTimeout,
+	}
+	c.index = newEvictionIndex[K, V](c.policy, &c.expiries)
+	c.setNegativePolicy(options.Negative)
+	return c
+}
+
+func (c *Cache[K, V]) Get(ctx context.Context, key K) (V, error) {
+	return c.Load(ctx, key, false, nil, 0)
+}
+
+func (c *Cache[K, V]) Load(
+	ctx context.Context,
+	key K,
+	forceNew bool,
+	specializedLoader Loader[K, V],
+	specializedExpiryTime time.Duration,
+) (V, error) {
+	c.mutex.Lock()
+	currentTime := time.Now()
+	evicted := c.cleanup(currentTime, nil)
+	if c.negatives != nil && !forceNew {
+		if negativeErr, found := c.negatives.Peek(key); found {
+			c.negativeHits++
+			c.mutex.Unlock()
+			c.notifyEvicted(evicted)
+			var zero V
+			return zero, negativeErr
+		}
+	}
+	entry, exists := c.entries[key]
+	if exists && !forceNew {
+		if entry.expiryTime.After(currentTime) {
+			c.hits++
+			c.index.touch(entry)
+			data := entry.data
+			c.mutex.Unlock()
+			c.notifyEvicted(evicted)
+			return data, nil
+		}
+		if c.stale.RevalidateInBackground {
+			c.staleHits++
+			c.index.touch(entry)
+			if _, loading := c.calls[key]; !loading {
+				c.refreshInBackground(ctx, key, specializedLoader, specializedExpiryTime)
+			}
+			data := entry.data
+			c.mutex.Unlock()
+			c.notifyEvicted(evicted)
+			return data, nil
+		}
+	}
+	if call, loading := c.calls[key]; loading {
+		c.coalesced++
+		c.mutex.Unlock()
+		c.notifyEvicted(evicted)
+		data, err := call.wait(ctx)
+		if err != nil {
+			return c.fallbackToStale(ctx, key, data, err)
+		}
+		return data, nil
+	}
+
+	c.misses++
+	call, err := c.startCall(key, specializedLoader, specializedExpiryTime)
+	c.mutex.Unlock()
+	c.notifyEvicted(evicted)
+	if err != nil {
+		var zero V
+		return zero, err
+	}
+
+	go call.run(ctx)
+	data, err := call.wait(ctx)
+	if err != nil {
+		return c.fallbackToStale(ctx, key, data, err)
+	}
+	return data, nil
+}
+
+func (c *Cache[K, V]) startCall(
+	key K, specializedLoader Loader[K, V], specializedExpiryTime time.Duration,
+) (*inflightCall[V], error) {
+	loader := specializedLoader
+	if loader == nil {
+		loader = c.defaultLoader
+	}
+	if loader == nil {
+		return nil, errors.New("infuzu/utils/cache.go no loader configured")
+	}
+	expiryTime := c.defaultExpiryTime
+	if specializedExpiryTime != 0 {
+		expiryTime = specializedExpiryTime
+	}
+	call := &inflightCall[V]{done: make(chan struct{})}
+	loadTimeout := c.loadTimeout
+	// The load is shared, so it outlives the caller that started it; each
+	// waiter stops waiting when its own context ends.
+	call.run = func(ctx context.Context) {
+		ctx = context.WithoutCancel(ctx)
+		if loadTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, loadTimeout)
+			defer cancel()
+		}
+		c.runLoader(ctx, key, loader, expiryTime, call)
+	}
+	c.calls[key] = call
+	return call, nil
+}
+
+func (c *Cache[K, V]) refreshInBackground(
+	ctx context.Context, key K, specializedLoader Loader[K, V], specializedExpiryTime time.Duration,
+) {
+	call, err := c.startCall(key, specializedLoader, specializedExpiryTime)
+	if err != nil {
+		return
+	}
+	c.refreshes++
+	go func() {
+		call.run(ctx)
+		if call.err != nil {
+			c.mutex.Lock()
+			c.refreshErrors++
+			c.mutex.Unlock()
+		}
+	}()
+}
+
+// A caller that gave up is told so rather than handed stale data; only
+// failures of the load itself fall back to the stale entry.
+func (c *Cache[K, V]) fallbackToStale(ctx context.Context, key K, data V, err error) (V, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if !c.stale.ServeOnError || c.isNegative(err) || ctx.Err() != nil {
+		return data, err
+	}
+	entry, exists := c.entries[key]
+	if !exists || !entry.expiryTime.Add(c.stale.MaxStale).After(time.Now()) {
+		return data, err
+	}
+	c.staleOnError++
+	return entry.data, nil
+}
+
+func (c *Cache[K, V]) runLoader(
+	ctx context.Context, key K, loader Loader[K, V], expiryTime time.Duration, call *inflightCall[V],
+) {
+	var evicted []evictedItem[K, V]
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			call.err = fmt.Errorf("infuzu/utils/cache.go loader panicked: %v", recovered)
+		}
+		c.mutex.Lock()
+		if c.calls[key] == call {
+			delete(c.calls, key)
+		}
+		if !call.invalidated {
+			if call.err == nil {
+				evicted = c.store(key, call.data, time.Now().Add(expiryTime), evicted)
+				if c.negatives != nil {
+					c.negatives.Remove(key)
+				}
+			} else if c.isNegative(call.err) {
+				if entry, exists := c.entries[key]; exists {
+					c.unlink(entry)
+				}
+				c.negatives.Set(key, call.err, 0)
+			}
+		}
+		c.mutex.Unlock()
+		close(call.done)
+		c.notifyEvicted(evicted)
+	}()
+	call.data, call.err = loader(ctx, key)
+}
+
+func (c *Cache[K, V]) isNegative(err error) bool {
+	return c.negatives != nil && c.negative.IsNegative != nil && c.negative.IsNegative(err)
+}
+
+func (c *Cache[K, V]) Peek(key K) (V, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	entry, exists := c.entries[key]
+	if !exists || !entry.expiryTime.After(time.Now()) {
+		var zero V
+		return zero, false
+	}
+	return entry.data, true
+}
+
+func (c *Cache[K, V]) Set(key K, value V, expiryTime time.Duration) {
+	c.mutex.Lock()
+	var evicted []evictedItem[K, V]
+	defer func() {
+		c.mutex.Unlock()
+		c.notifyEvicted(evicted)
+	}()
+
+	if expiryTime == 0 {
+		expiryTime = c.defaultExpiryTime
+	}
+	currentTime := time.Now()
+	evicted = c.cleanup(currentTime, evicted)
+	evicted = c.store(key, value, currentTime.Add(expiryTime), evicted)
+	if c.negatives != nil {
+		c.negatives.Remove(key)
+	}
+}
+
+func (c *Cache[K, V]) Remove(key K) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if entry, exists := c.entries[key]; exists {
+		c.unlink(entry)
+	}
+	if call, loading := c.calls[key]; loading {
+		call.invalidated = true
+		delete(c.calls, key)
+	}
+	if c.negatives != nil {
+		c.negatives.Remove(key)
+	}
+}
+
+func (c *Cache[K, V]) SetMaxSize(maxSize int) {
+	c.mutex.Lock()
+	var evicted []evictedItem[K, V]
+	defer func() {
+		c.mutex.Unlock()
+		c.notifyEvicted(evicted)
+	}()
+
+	c.maxSize = maxSize
+	evicted = c.cleanup(time.Now(), evicted)
+	evicted = c.ensureMaxSize(c.maxSize, evicted)
+}
+
+func (c *Cache[K, V]) SetStalePolicy(policy StalePolicy) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.stale = policy
+}
+
+func (c *Cache[K, V]) SetNegativePolicy(policy NegativePolicy) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.setNegativePolicy(policy)
+}
+
+func (c *Cache[K, V]) setNegativePolicy(policy NegativePolicy) {
+	c.negative = policy
+	if policy.ExpiryTime <= 0 || policy.IsNegative == nil {
+		c.negatives = nil
+		return
+	}
+	maxSize := policy.MaxSize
+	if maxSize == 0 {
+		maxSize = c.maxSize
+	}
+	c.negatives = NewCache[K, error](nil, policy.ExpiryTime, maxSize)
+}
+
+func (c *Cache[K, V]) SetEvictionCallback(callback EvictionCallback[K, V]) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.onEviction = callback
+}
+
+func (c *Cache[K, V]) store(
+	key K, data V, expiryTime time.Time, evicted []evictedItem[K, V],
+) []evictedItem[K, V] {
+	if entry, exists := c.entries[key]; exists {
+		entry.data = data
+		entry.expiryTime = expiryTime
+		heap.Fix(&c.expiries, entry.heapIndex)
+		c.index.touch(entry)
+		return evicted
+	}
+	if c.maxSize > 0 {
+		evicted = c.ensureMaxSize(c.maxSize-1, evicted)
+	}
+	entry := &cacheItem[K, V]{
+		key:        key,
+		data:       data,
+		expiryTime: expiryTime,
+	}
+	c.entries[key] = entry
+	heap.Push(&c.expiries, entry)
+	c.index.add(entry)
+	return evicted
+}
+
+func (c *Cache[K, V]) unlink(entry *cacheItem[K, V]) {
+	delete(c.entries, entry.key)
+	if entry.heapIndex >= 0 {
+		heap.Remove(&c.expiries, entry.heapIndex)
+	}
+	c.index.remove(entry)
+}
+
+func (c *Cache[K, V]) cleanup(currentTime time.Time, evicted []evictedItem[K, V]) []evictedItem[K, V] {
+	for c.expiries.Len() > 0 && !c.expiries[0].expiryTime.Add(c.stale.MaxStale).After(currentTime) {
+		entry := c.expiries[0]
+		c.unlink(entry)
+		c.expirations++
+		evicted = append(evicted, evictedItem[K, V]{key: entry.key, data: entry.data, reason: EvictionReasonExpired})
+	}
+	return evicted
+}
+
+func (c *Cache[K, V]) ensureMaxSize(maxSize int, evicted []evictedItem[K, V]) []evictedItem[K, V] {
+	for c.maxSize > 0 && len(c.entries) > maxSize {
+		entry := c.index.victim()
+		if entry == nil {
+			break
+		}
+		c.unlink(entry)
+		c.evictions++
+		evicted = append(evicted, evictedItem[K, V]{key: entry.key, data: entry.data, reason: EvictionReasonCapacity})
+	}
+	return evicted
+}
+
+func (c *Cache[K, V]) notifyEvicted(evicted []evictedItem[K, V]) {
+	if len(evicted) == 0 {
+		return
+	}
+	c.mutex.Lock()
+	onEviction := c.onEviction
+	c.mutex.Unlock()
+	if onEviction == nil {
+		return
+	}
+	for _, item := range evicted {
+		onEviction(item.key, item.data, item.reason)
+	}
+}
+
+func (c *Cache[K, V]) GetStats() map[string]int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	negativeEntries := 0
+	if c.negatives != nil {
+		negativeEntries = c.negatives.Len()
+	}
+	return map[string]int{
+		"hits":           c.hits,
+		"misses":         c.misses,
+		"size":           len(c.entries),
+		"evictions":      c.evictions,
+		"expirations":    c.expirations,
+		"coalesced":      c.coalesced,
+		"stale_hits":     c.staleHits,
+		"stale_on_error": c.staleOnError,
+		"refreshes":      c.refreshes,
+		"refresh_errors": c.refreshErrors,
+		"negative_hits":  c.negativeHits,
+		"negative_size":  negativeEntries,
+	}
+}
+
+func (c *Cache[K, V]) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return len(c.entries)
+}
@@ -0,0 +1,193 @@
+package infuzu
+
+import "container/list"
+
+// EvictionPolicy picks the entry dropped when a cache is full. LRU and LFU
+// pick and update their victim in O(1). TTL drops the entry closest to expiry
+// by reading the expiry heap: entries can carry different expiry times, so
+// keeping them ordered costs O(log n) per insert, for every policy.
+type EvictionPolicy int
+
+const (
+	EvictionPolicyLRU EvictionPolicy = iota
+	EvictionPolicyLFU
+	EvictionPolicyTTL
+)
+
+func (p EvictionPolicy) String() string {
+	switch p {
+	case EvictionPolicyLRU:
+		return "lru"
+	case EvictionPolicyLFU:
+		return "lfu"
+	case EvictionPolicyTTL:
+		return "ttl"
+	default:
+		return "unknown"
+	}
+}
+
+type EvictionReason int
+
+const (
+	EvictionReasonCapacity EvictionReason = iota
+	EvictionReasonExpired
+)
+
+func (r EvictionReason) String() string {
+	switch r {
+	case EvictionReasonCapacity:
+		return "capacity"
+	case EvictionReasonExpired:
+		return "expired"
+	default:
+		return "unknown"
+	}
+}
+
+type evictionIndex[K comparable, V any] interface {
+	add(item *cacheItem[K, V])
+	touch(item *cacheItem[K, V])
+	remove(item *cacheItem[K, V])
+	victim() *cacheItem[K, V]
+}
+
+func newEvictionIndex[K comparable, V any](
+	policy EvictionPolicy, expiries *expiryHeap[K, V],
+) evictionIndex[K, V] {
+	switch policy {
+	case EvictionPolicyLFU:
+		return &lfuIndex[K, V]{buckets: list.New()}
+	case EvictionPolicyTTL:
+		return &ttlIndex[K, V]{expiries: expiries}
+	default:
+		return &lruIndex[K, V]{items: list.New()}
+	}
+}
+
+type lruIndex[K comparable, V any] struct {
+	items *list.List
+}
+
+func (idx *lruIndex[K, V]) add(item *cacheItem[K, V]) {
+	item.element = idx.items.PushFront(item)
+}
+
+func (idx *lruIndex[K, V]) touch(item *cacheItem[K, V]) {
+	idx.items.MoveToFront(item.element)
+}
+
+func (idx *lruIndex[K, V]) remove(item *cacheItem[K, V]) {
+	idx.items.Remove(item.element)
+	item.element = nil
+}
+
+func (idx *lruIndex[K, V]) victim() *cacheItem[K, V] {
+	back := idx.items.Back()
+	if back == nil {
+		return nil
+	}
+	return back.Value.(*cacheItem[K, V])
+}
+
+type lfuBucket struct {
+	frequency int
+	items     *list.List
+}
+
+type lfuIndex[K comparable, V any] struct {
+	buckets *list.List
+}
+
+func (idx *lfuIndex[K, V]) add(item *cacheItem[K, V]) {
+	front := idx.buckets.Front()
+	if front == nil || front.Value.(*lfuBucket).frequency != 1 {
+		front = idx.buckets.PushFront(&lfuBucket{frequency: 1, items: list.New()})
+	}
+	item.bucket = front
+	item.element = front.Value.(*lfuBucket).items.PushFront(item)
+}
+
+func (idx *lfuIndex[K, V]) touch(item *cacheItem[K, V]) {
+	current := item.bucket
+	bucket := current.Value.(*lfuBucket)
+	next := current.Next()
+	if next == nil || next.Value.(*lfuBucket).frequency != bucket.frequency+1 {
+		next = idx.buckets.InsertAfter(&lfuBucket{frequency: bucket.frequency + 1, items: list.New()}, current)
+	}
+	bucket.items.Remove(item.element)
+	if bucket.items.Len() == 0 {
+		idx.buckets.Remove(current)
+	}
+	item.bucket = next
+	item.element = next.Value.(*lfuBucket).items.PushFront(item)
+}
+
+func (idx *lfuIndex[K, V]) remove(item *cacheItem[K, V]) {
+	bucket := item.bucket.Value.(*lfuBucket)
+	bucket.items.Remove(item.element)
+	if bucket.items.Len() == 0 {
+		idx.buckets.Remove(item.bucket)
+	}
+	item.bucket = nil
+	item.element = nil
+}
+
+func (idx *lfuIndex[K, V]) victim() *cacheItem[K, V] {
+	front := idx.buckets.Front()
+	if front == nil {
+		return nil
+	}
+	back := front.Value.(*lfuBucket).items.Back()
+	if back == nil {
+		return nil
+	}
+	return back.Value.(*cacheItem[K, V])
+}
+
+type ttlIndex[K comparable, V any] struct {
+	expiries *expiryHeap[K, V]
+}
+
+func (idx *ttlIndex[K, V]) add(*cacheItem[K, V]) {}
+
+func (idx *ttlIndex[K, V]) touch(*cacheItem[K, V]) {}
+
+func (idx *ttlIndex[K, V]) remove(*cacheItem[K, V]) {}
+
+func (idx *ttlIndex[K, V]) victim() *cacheItem[K, V] {
+	if idx.expiries.Len() == 0 {
+		return nil
+	}
+	return (*idx.expiries)[0]
+}
+
+type expiryHeap[K comparable, V any] []*cacheItem[K, V]
+
+func (h expiryHeap[K, V]) Len() int { return len(h) }
+
+func (h expiryHeap[K, V]) Less(i, j int) bool {
+	return h[i].expiryTime.Before(h[j].expiryTime)
+}
+
+func (h expiryHeap[K, V]) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].heapIndex = i
+	h[j].heapIndex = j
+}
+
+func (h *expiryHeap[K, V]) Push(x interface{}) {
+	item := x.(*cacheItem[K, V])
+	item.heapIndex = len(*h)
+	*h = append(*h, item)
+}
+
+func (h *expiryHeap[K, V]) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	item.heapIndex = -1
+	*h = old[:n-1]
+	return item
+}
@@ -0,0 +1,557 @@
+package infuzu
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCacheTracksHitsAndMisses(t *testing.T) {
+	var loads int32
+	cache := NewCache(func(ctx context.Context, key int) (string, error) {
+		atomic.AddInt32(&loads, 1)
+		if key < 0 {
+			return "", errors.New("negative key")
+		}
+		return fmt.Sprint("value-", key), nil
+	}, time.Minute, 0)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if value, err := cache.Get(ctx, 1); err != nil || value != "value-1" {
+			t.Fatalf("unexpected result %q, %v", value, err)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := cache.Get(ctx, -1); err == nil {
+			t.Fatal("expected the loader error")
+		}
+	}
+	if stats := cache.GetStats(); stats["hits"] != 2 || stats["misses"] != 3 || stats["size"] != 1 {
+		t.Fatalf("unexpected stats %v", stats)
+	}
+
+	value, err := cache.Load(ctx, 1, true, func(context.Context, int) (string, error) {
+		return "specialized", nil
+	}, 10*time.Millisecond)
+	if err != nil || value != "specialized" {
+		t.Fatalf("unexpected forced load %q, %v", value, err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if value, _ = cache.Get(ctx, 1); value != "value-1" {
+		t.Fatalf("the specialized expiry time was not applied, got %q", value)
+	}
+	if loads := atomic.LoadInt32(&loads); loads != 4 {
+		t.Fatalf("expected 4 default loads, got %d", loads)
+	}
+}
+
+func TestCacheSystemCallsFetchFunctions(t *testing.T) {
+	cacheSystem := NewCacheSystem(func(ctx context.Context, name string, count int) (string, error) {
+		return fmt.Sprint(name, "-", count), nil
+	}, 60, 10)
+
+	value, err := cacheSystem.Get("key", false, nil, 0, "item", 3)
+	if err != nil || value != "item-3" {
+		t.Fatalf("unexpected result %v, %v", value, err)
+	}
+	if value, _ = cacheSystem.Get("key", false, nil, 0, "other", 4); value != "item-3" {
+		t.Fatalf("expected the cached value, got %v", value)
+	}
+	value, err = cacheSystem.Get("plain", false, func(name string) string { return "plain-" + name }, 0, "item")
+	if err != nil || value != "plain-item" {
+		t.Fatalf("unexpected result %v, %v", value, err)
+	}
+
+	if _, err = cacheSystem.Get("arity", false, nil, 0, "item"); err == nil {
+		t.Fatal("expected an arity error")
+	}
+	if _, err = cacheSystem.Get("types", false, nil, 0, 3, "item"); err == nil {
+		t.Fatal("expected an error for mismatched argument types")
+	}
+	if stats := cacheSystem.GetStats(); stats["size"] != 2 {
+		t.Fatalf("failed fetches should not be cached: %v", stats)
+	}
+}
+
+type evictionRecorder struct {
+	mutex   sync.Mutex
+	evicted []string
+}
+
+func (r *evictionRecorder) record(key string, _ int, reason EvictionReason) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.evicted = append(r.evicted, key+":"+reason.String())
+}
+
+func (r *evictionRecorder) keys() string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return strings.Join(r.evicted, ",")
+}
+
+func TestCacheEvictionOrder(t *testing.T) {
+	cases := map[EvictionPolicy]struct {
+		expiries []time.Duration
+		reads    []string
+		expected string
+	}{
+		EvictionPolicyLRU: {reads: []string{"a", "b", "a"}, expected: "c:capacity,b:capacity"},
+		EvictionPolicyLFU: {reads: []string{"a", "a", "c", "b", "b"}, expected: "c:capacity,d:capacity"},
+		EvictionPolicyTTL: {
+			expiries: []time.Duration{3 * time.Minute, time.Minute, 2 * time.Minute},
+			reads:    []string{"b", "b", "b"},
+			expected: "b:capacity,c:capacity",
+		},
+	}
+	for policy, testCase := range cases {
+		t.Run(policy.String(), func(t *testing.T) {
+			recorder := &evictionRecorder{}
+			cache := NewCacheWithOptions[string, int](nil, CacheOptions[string, int]{
+				ExpiryTime:     time.Hour,
+				MaxSize:        3,
+				EvictionPolicy: policy,
+				OnEviction:     recorder.record,
+			})
+			for i, key := range []string{"a", "b", "c"} {
+				var expiry time.Duration
+				if testCase.expiries != nil {
+					expiry = testCase.expiries[i]
+				}
+				cache.Set(key, i, expiry)
+			}
+			for _, key := range testCase.reads {
+				if _, err := cache.Get(context.Background(), key); err != nil {
+					t.Fatal(err)
+				}
+			}
+			cache.Set("d", 3, 4*time.Minute)
+			cache.Set("e", 4, 5*time.Minute)
+			if evicted := recorder.keys(); evicted != testCase.expected {
+				t.Fatalf("expected %s, got %s", testCase.expected, evicted)
+			}
+			if stats := cache.GetStats(); stats["evictions"] != 2 || stats["size"] != 3 || cache.Len() != 3 {
+				t.Fatalf("unexpected stats %v", stats)
+			}
+		})
+	}
+}
+
+func TestCacheReportsExpirationsAndShrinking(t *testing.T) {
+	recorder := &evictionRecorder{}
+	cache := NewCacheWithOptions[string, int](nil, CacheOptions[string, int]{
+		ExpiryTime: time.Hour,
+		OnEviction: recorder.record,
+	})
+	cache.Set("short", 0, 5*time.Millisecond)
+	for i, key := range []string{"a", "b", "c"} {
+		cache.Set(key, i, 0)
+	}
+	time.Sleep(10 * time.Millisecond)
+	cache.SetMaxSize(1)
+	if evicted := recorder.keys(); evicted != "short:expired,a:capacity,b:capacity" {
+		t.Fatalf("unexpected evictions %s", evicted)
+	}
+	cache.Set("d", 3, 0)
+	if evicted := recorder.keys(); evicted != "short:expired,a:capacity,b:capacity,c:capacity" {
+		t.Fatalf("unexpected evictions %s", evicted)
+	}
+	if stats := cache.GetStats(); stats["evictions"] != 3 || stats["expirations"] != 1 || stats["size"] != 1 {
+		t.Fatalf("unexpected stats %v", stats)
+	}
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for condition")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestCacheSystemCoalescesConcurrentLoads(t *testing.T) {
+	const keys, callersPerKey = 8, 16
+	var loads [keys]int32
+	release := make(chan struct{})
+	cacheSystem := NewCacheSystem(func(ctx context.Context, key int) (interface{}, error) {
+		atomic.AddInt32(&loads[key], 1)
+		<-release
+		return fmt.Sprint("value-", key), nil
+	}, 60, 0)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, keys*callersPerKey)
+	for key := 0; key < keys; key++ {
+		for i := 0; i < callersPerKey; i++ {
+			wg.Add(1)
+			go func(key int) {
+				defer wg.Done()
+				value, err := cacheSystem.GetContext(context.Background(), fmt.Sprint(key), false, nil, 0, key)
+				if err == nil && value != fmt.Sprint("value-", key) {
+					err = fmt.Errorf("key %d got %v", key, value)
+				}
+				errs <- err
+			}(key)
+		}
+	}
+	waitFor(t, func() bool {
+		stats := cacheSystem.GetStats()
+		return stats["misses"] == keys && stats["coalesced"] == keys*(callersPerKey-1)
+	})
+	close(release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	for key, count := range loads {
+		if count != 1 {
+			t.Fatalf("loader ran %d times for key %d", count, key)
+		}
+	}
+}
+
+func TestCacheWaitersAreCancelledIndependently(t *testing.T) {
+	release := make(chan struct{})
+	loaderErr := make(chan error, 1)
+	cache := NewCache(func(ctx context.Context, key string) (string, error) {
+		<-release
+		loaderErr <- ctx.Err()
+		return "loaded", nil
+	}, time.Minute, 0)
+
+	firstCtx, cancelFirst := context.WithCancel(context.Background())
+	firstResult := make(chan error, 1)
+	go func() {
+		_, err := cache.Get(firstCtx, "key")
+		firstResult <- err
+	}()
+	waitFor(t, func() bool { return cache.GetStats()["misses"] == 1 })
+
+	secondResult := make(chan string, 1)
+	go func() {
+		value, _ := cache.Get(context.Background(), "key")
+		secondResult <- value
+	}()
+	waitFor(t, func() bool { return cache.GetStats()["coalesced"] == 1 })
+
+	cancelFirst()
+	if err := <-firstResult; !errors.Is(err, context.Canceled) {
+		t.Fatalf("cancelled caller should stop waiting, got %v", err)
+	}
+	select {
+	case value := <-secondResult:
+		t.Fatalf("second caller returned %q before the load finished", value)
+	default:
+	}
+
+	close(release)
+	if value := <-secondResult; value != "loaded" {
+		t.Fatalf("second caller got %q", value)
+	}
+	if err := <-loaderErr; err != nil {
+		t.Fatalf("shared load saw the first caller's cancellation: %v", err)
+	}
+	if stats := cache.GetStats(); stats["size"] != 1 || stats["misses"] != 1 {
+		t.Fatal("the shared load result was not cached")
+	}
+}
+
+func TestCacheLoadTimeoutBoundsSharedLoad(t *testing.T) {
+	cache := NewCacheWithOptions(func(ctx context.Context, key string) (string, error) {
+		<-ctx.Done()
+		return "", ctx.Err()
+	}, CacheOptions[string, string]{ExpiryTime: time.Minute, LoadTimeout: 10 * time.Millisecond})
+	if _, err := cache.Get(context.Background(), "key"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the load timeout to end the load, got %v", err)
+	}
+}
+
+func TestCacheContainsLoaderPanics(t *testing.T) {
+	release := make(chan struct{})
+	cache := NewCache(func(ctx context.Context, key string) (string, error) {
+		<-release
+		panic("boom")
+	}, time.Minute, 0)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 4)
+	for i := 0; i < cap(errs); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := cache.Get(context.Background(), "key")
+			errs <- err
+		}()
+	}
+	waitFor(t, func() bool { return cache.GetStats()["coalesced"] == cap(errs)-1 })
+	close(release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err == nil || !strings.Contains(err.Error(), "panicked") {
+			t.Fatalf("expected a contained panic, got %v", err)
+		}
+	}
+
+	value, err := cache.Load(context.Background(), "key", false, func(context.Context, string) (string, error) {
+		return "recovered", nil
+	}, 0)
+	if err != nil || value != "recovered" {
+		t.Fatalf("cache did not recover after a panic: %q, %v", value, err)
+	}
+}
+
+func TestCacheRemoveInvalidatesInflightLoad(t *testing.T) {
+	var loads int32
+	release := make(chan struct{})
+	cache := NewCache(func(ctx context.Context, key string) (string, error) {
+		if atomic.AddInt32(&loads, 1) == 1 {
+			<-release
+			return "outdated", nil
+		}
+		return "current", nil
+	}, time.Minute, 0)
+
+	result := make(chan string, 1)
+	go func() {
+		value, _ := cache.Get(context.Background(), "key")
+		result <- value
+	}()
+	waitFor(t, func() bool { return atomic.LoadInt32(&loads) == 1 })
+	cache.Remove("key")
+	close(release)
+	if value := <-result; value != "outdated" {
+		t.Fatalf("the waiting caller should still get its load, got %q", value)
+	}
+	if cache.GetStats()["size"] != 0 {
+		t.Fatal("a load started before Remove repopulated the cache")
+	}
+	if value, _ := cache.Get(context.Background(), "key"); value != "current" {
+		t.Fatalf("expected a fresh load after Remove, got %q", value)
+	}
+}
+
+func TestCacheServesStaleOnErrorWithinMaxStale(t *testing.T) {
+	upstreamErr := errors.New("upstream unavailable")
+	cache := NewCacheWithOptions(func(ctx context.Context, key string) (string, error) {
+		return "", upstreamErr
+	}, CacheOptions[string, string]{
+		ExpiryTime: 10 * time.Millisecond,
+		Stale:      StalePolicy{MaxStale: 50 * time.Millisecond, ServeOnError: true},
+	})
+	cache.Set("key", "stale", 0)
+
+	time.Sleep(20 * time.Millisecond)
+	if value, err := cache.Get(context.Background(), "key"); err != nil || value != "stale" {
+		t.Fatalf("expected the stale value, got %q, %v", value, err)
+	}
+	if stats := cache.GetStats(); stats["stale_on_error"] != 1 || stats["size"] != 1 {
+		t.Fatalf("unexpected stats %v", stats)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if _, err := cache.Get(context.Background(), "key"); !errors.Is(err, upstreamErr) {
+		t.Fatalf("expected the error once MaxStale has passed, got %v", err)
+	}
+	if stats := cache.GetStats(); stats["expirations"] != 1 || stats["size"] != 0 {
+		t.Fatalf("the entry was not dropped after MaxStale: %v", stats)
+	}
+}
+
+func TestCacheDoesNotServeStaleToCallersThatGaveUp(t *testing.T) {
+	release := make(chan struct{})
+	cache := NewCacheWithOptions(func(ctx context.Context, key string) (string, error) {
+		<-release
+		return "", errors.New("upstream unavailable")
+	}, CacheOptions[string, string]{
+		ExpiryTime: time.Millisecond,
+		Stale:      StalePolicy{MaxStale: time.Minute, ServeOnError: true},
+	})
+	cache.Set("key", "stale", 0)
+	time.Sleep(5 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if value, err := cache.Get(ctx, "key"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the caller's deadline, got %q, %v", value, err)
+	}
+	close(release)
+	if value, err := cache.Get(context.Background(), "key"); err != nil || value != "stale" {
+		t.Fatalf("a failed load should still serve stale data, got %q, %v", value, err)
+	}
+}
+
+func TestCacheServesStaleWhenLoadTimesOut(t *testing.T) {
+	cache := NewCacheWithOptions(func(ctx context.Context, key string) (string, error) {
+		<-ctx.Done()
+		return "", ctx.Err()
+	}, CacheOptions[string, string]{
+		ExpiryTime:  time.Millisecond,
+		Stale:       StalePolicy{MaxStale: time.Minute, ServeOnError: true},
+		LoadTimeout: 10 * time.Millisecond,
+	})
+	cache.Set("key", "stale", 0)
+	time.Sleep(5 * time.Millisecond)
+	if value, err := cache.Get(context.Background(), "key"); err != nil || value != "stale" {
+		t.Fatalf("a timed out load should serve stale data, got %q, %v", value, err)
+	}
+}
+
+func TestCacheRevalidatesInBackground(t *testing.T) {
+	var loads int32
+	release := make(chan struct{})
+	cache := NewCacheWithOptions(func(ctx context.Context, key string) (string, error) {
+		atomic.AddInt32(&loads, 1)
+		<-release
+		return "fresh", nil
+	}, CacheOptions[string, string]{
+		ExpiryTime: 10 * time.Millisecond,
+		Stale:      StalePolicy{MaxStale: time.Minute, RevalidateInBackground: true},
+	})
+	cache.Set("key", "stale", 0)
+	time.Sleep(20 * time.Millisecond)
+
+	for i := 0; i < 3; i++ {
+		if value, err := cache.Get(context.Background(), "key"); err != nil || value != "stale" {
+			t.Fatalf("expected the stale value while revalidating, got %q, %v", value, err)
+		}
+	}
+	close(release)
+	waitFor(t, func() bool {
+		value, found := cache.Peek("key")
+		return found && value == "fresh"
+	})
+	if loads := atomic.LoadInt32(&loads); loads != 1 {
+		t.Fatalf("expected a single background refresh, got %d", loads)
+	}
+	if stats := cache.GetStats(); stats["stale_hits"] != 3 || stats["refreshes"] != 1 || stats["refresh_errors"] != 0 {
+		t.Fatalf("unexpected stats %v", stats)
+	}
+}
+
+func TestCacheNegativePolicy(t *testing.T) {
+	errMissing := errors.New("missing")
+	errUnavailable := errors.New("unavailable")
+	var loads int32
+	cache := NewCacheWithOptions(func(ctx context.Context, key string) (string, error) {
+		atomic.AddInt32(&loads, 1)
+		switch key {
+		case "missing":
+			return "", errMissing
+		case "flaky":
+			return "", errUnavailable
+		}
+		return "value", nil
+	}, CacheOptions[string, string]{
+		ExpiryTime: time.Minute,
+		Negative: NegativePolicy{
+			ExpiryTime: 20 * time.Millisecond,
+			IsNegative: func(err error) bool { return errors.Is(err, errMissing) },
+		},
+	})
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if _, err := cache.Get(ctx, "missing"); !errors.Is(err, errMissing) {
+			t.Fatalf("expected the cached error, got %v", err)
+		}
+	}
+	if loads := atomic.LoadInt32(&loads); loads != 1 {
+		t.Fatalf("negative results should be cached, loaded %d times", loads)
+	}
+	for i := 0; i < 2; i++ {
+		_, _ = cache.Get(ctx, "flaky")
+	}
+	if loads := atomic.LoadInt32(&loads); loads != 3 {
+		t.Fatalf("other errors should not be cached, loaded %d times", loads)
+	}
+	stats := cache.GetStats()
+	if stats["negative_hits"] != 2 || stats["negative_size"] != 1 || stats["size"] != 0 {
+		t.Fatalf("unexpected stats %v", stats)
+	}
+	if _, found := cache.Peek("missing"); found || cache.Len() != 0 {
+		t.Fatal("negative results should not be visible as entries")
+	}
+
+	if _, err := cache.Load(ctx, "missing", true, nil, 0); !errors.Is(err, errMissing) {
+		t.Fatalf("forceNew should reload, got %v", err)
+	}
+	if loads := atomic.LoadInt32(&loads); loads != 4 {
+		t.Fatalf("forceNew should bypass the negative cache, loaded %d times", loads)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	_, _ = cache.Get(ctx, "missing")
+	if loads := atomic.LoadInt32(&loads); loads != 5 {
+		t.Fatalf("expired negative results should be reloaded, loaded %d times", loads)
+	}
+
+	cache.Set("missing", "created", 0)
+	cache.Remove("missing")
+	if value, err := cache.Get(ctx, "value"); err != nil || value != "value" {
+		t.Fatalf("unexpected result %q, %v", value, err)
+	}
+	if stats := cache.GetStats(); stats["negative_size"] != 0 {
+		t.Fatalf("Remove should drop negative results: %v", stats)
+	}
+}
+
+func TestCacheNegativeResultReplacesEntry(t *testing.T) {
+	errMissing := errors.New("missing")
+	cache := NewCacheWithOptions(func(ctx context.Context, key string) (string, error) {
+		return "", errMissing
+	}, CacheOptions[string, string]{
+		ExpiryTime: time.Minute,
+		Negative: NegativePolicy{
+			ExpiryTime: time.Minute,
+			IsNegative: func(err error) bool { return errors.Is(err, errMissing) },
+		},
+	})
+	cache.Set("key", "deleted upstream", 0)
+	if _, err := cache.Load(context.Background(), "key", true, nil, 0); !errors.Is(err, errMissing) {
+		t.Fatalf("expected the negative result, got %v", err)
+	}
+	if _, found := cache.Peek("key"); found || cache.Len() != 0 {
+		t.Fatal("a negative result should drop the existing entry")
+	}
+	cache.Set("key", "recreated", 0)
+	if value, err := cache.Get(context.Background(), "key"); err != nil || value != "recreated" {
+		t.Fatalf("Set should take precedence over the negative result, got %q, %v", value, err)
+	}
+}
+
+func TestCachePeekAndLen(t *testing.T) {
+	cache := NewCache[string, string](nil, 20*time.Millisecond, 0)
+	if _, found := cache.Peek("key"); found || cache.Len() != 0 {
+		t.Fatal("an empty cache reported an entry")
+	}
+	cache.Set("key", "value", 0)
+	cache.Set("other", "value", time.Minute)
+	if value, found := cache.Peek("key"); !found || value != "value" || cache.Len() != 2 {
+		t.Fatalf("unexpected peek %q, %v with %d entries", value, found, cache.Len())
+	}
+	if stats := cache.GetStats(); stats["hits"] != 0 || stats["misses"] != 0 {
+		t.Fatalf("Peek should not count as a lookup: %v", stats)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if _, found := cache.Peek("key"); found {
+		t.Fatal("Peek returned an expired entry")
+	}
+	cache.Set("third", "value", time.Minute)
+	if cache.Len() != 2 {
+		t.Fatalf("expired entries should be purged, got %d entries", cache.Len())
+	}
+}
@@ -1,34 +1,25 @@
 package infuzu
 
 import (
+	"context"
 	"fmt"
 	"reflect"
-	"sync"
 	"time"
 )
 
-type cacheEntry struct {
-	Data       interface{}
-	ExpiryTime int64
-}
-
 type CacheSystem struct {
-	cache                map[string]*cacheEntry
+	cache                *Cache[string, interface{}]
 	DefaultFetchFunction interface{}
 	DefaultExpiryTime    int64
 	MaxSize              int
-	hits                 int
-	misses               int
-	mutex                sync.Mutex
 }
 
 func NewCacheSystem(defaultFetchFunction interface{}, defaultExpiryTime int64, maxSize int) *CacheSystem {
 	return &CacheSystem{
-		cache:                make(map[string]*cacheEntry),
+		cache:                NewCache[string, interface{}](nil, time.Duration(defaultExpiryTime)*time.Second, maxSize),
 		DefaultFetchFunction: defaultFetchFunction,
 		DefaultExpiryTime:    defaultExpiryTime,
 		MaxSize:              maxSize,
-		mutex:                sync.Mutex{},
 	}
 }
 
@@ -39,39 +30,50 @@ func (cs *CacheSystem) Get(
 	specializedExpiryTime int64,
 	args ...interface{},
 ) (interface{}, error) {
-	cs.mutex.Lock()
-	defer cs.mutex.Unlock()
-
-	cs.cleanup()
-	currentTime := time.Now().Unix()
-	if !forceNew {
-		if entry, exists := cs.cache[cacheKeyName]; exists {
-			if entry.ExpiryTime > currentTime {
-				cs.hits++
-				return entry.Data, nil
-			}
-		}
-	}
+	return cs.GetContext(
+		context.Background(), cacheKeyName, forceNew, specializedFetchFunction, specializedExpiryTime, args...,
+	)
+}
 
-	cs.misses++
+func (cs *CacheSystem) GetContext(
+	ctx context.Context,
+	cacheKeyName string,
+	forceNew bool,
+	specializedFetchFunction interface{},
+	specializedExpiryTime int64,
+	args ...interface{},
+) (interface{}, error) {
 	fetchFunction := specializedFetchFunction
 	if fetchFunction == nil {
 		fetchFunction = cs.DefaultFetchFunction
 	}
-	data, err := callFunction(fetchFunction, args...)
-	if err != nil {
-		return data, err
-	}
 	expiryTime := cs.DefaultExpiryTime
 	if specializedExpiryTime != 0 {
 		expiryTime = specializedExpiryTime
 	}
-	cs.cache[cacheKeyName] = &cacheEntry{
-		Data:       data,
-		ExpiryTime: currentTime + expiryTime,
+	cs.cache.SetMaxSize(cs.MaxSize)
+	return cs.cache.Load(
+		ctx,
+		cacheKeyName,
+		forceNew,
+		func(loadCtx context.Context, _ string) (interface{}, error) {
+			return callFunction(fetchFunction, withContextArgument(loadCtx, fetchFunction, args)...)
+		},
+		time.Duration(expiryTime)*time.Second,
+	)
+}
+
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+
+func withContextArgument(ctx context.Context, fn interface{}, args []interface{}) []interface{} {
+	fnType := reflect.TypeOf(fn)
+	if fnType == nil || fnType.Kind() != reflect.Func || fnType.NumIn() != len(args)+1 {
+		return args
+	}
+	if fnType.In(0) != contextType {
+		return args
 	}
-	cs.ensureMaxSize()
-	return data, nil
+	return append([]interface{}{ctx}, args...)
 }
 
 func callFunction(fn interface{}, args ...interface{}) (interface{}, error) {
@@ -97,37 +99,9 @@ func callFunction(fn interface{}, args ...interface{}) (interface{}, error) {
 }
 
 func (cs *CacheSystem) Remove(cacheKeyName string) {
-	cs.mutex.Lock()
-	defer cs.mutex.Unlock()
-	delete(cs.cache, cacheKeyName)
-}
-
-func (cs *CacheSystem) cleanup() {
-	currentTime := time.Now().Unix()
-	for key, entry := range cs.cache {
-		if entry.ExpiryTime <= currentTime {
-			delete(cs.cache, key)
-		}
-	}
-}
-
-func (cs *CacheSystem) ensureMaxSize() {
-	if cs.MaxSize > 0 && len(cs.cache) > cs.MaxSize {
-		for key := range cs.cache {
-			delete(cs.cache, key)
-			if len(cs.cache) <= cs.MaxSize {
-				break
-			}
-		}
-	}
+	cs.cache.Remove(cacheKeyName)
 }
 
 func (cs *CacheSystem) GetStats() map[string]int {
-	cs.mutex.Lock()
-	defer cs.mutex.Unlock()
-	return map[string]int{
-		"hits":   cs.hits,
-		"misses": cs.misses,
-		"size":   len(cs.cache),
-	}
+	return cs.cache.GetStats()
 }
@@ -3,25 +3,145 @@ package infuzu
 import (
 	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
 )
 
-func GetSignatureVersion(signature string) string {
-	var decodedSignature []byte
-	var err error
-	decodedSignature, err = base64.URLEncoding.DecodeString(signature)
+var (
+	ErrMalformedSignature = errors.New("infuzu/utils/signatures.go signature is malformed")
+	ErrUnsupportedVersion = errors.New("infuzu/utils/signatures.go signature version is not supported")
+	ErrKeyIDNotFound      = errors.New("infuzu/utils/signatures.go signature key id is missing")
+)
+
+const maxSignatureTimestamp = 1 << 53
+
+type ParsedSignature struct {
+	Version          string
+	Algorithm        string
+	KeyPairID        string
+	Timestamp        int64
+	Signature        []byte
+	EncodedSignature string
+	SignedHeaders    []string
+	Nonce            string
+}
+
+type signatureFields struct {
+	keyPairID string
+	timestamp string
+	signature string
+}
+
+var signatureFieldsByVersion = map[string]signatureFields{
+	"1.0": {keyPairID: "id", timestamp: "timestamp", signature: "signature"},
+	"1.2": {keyPairID: "i", timestamp: "t", signature: "s"},
+	"2.0": {keyPairID: "i", timestamp: "t", signature: "s"},
+}
+
+func decodeSignature(signature string) (map[string]interface{}, error) {
+	decodedSignature, err := base64.URLEncoding.DecodeString(signature)
 	if err != nil {
-		return "1.0"
+		return nil, fmt.Errorf("%w: %v", ErrMalformedSignature, err)
 	}
-
 	var signatureMap map[string]interface{}
-	err = json.Unmarshal(decodedSignature, &signatureMap)
-	if err != nil {
-		return "1.0"
+	if err = json.Unmarshal(decodedSignature, &signatureMap); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformedSignature, err)
 	}
+	if signatureMap == nil {
+		return nil, fmt.Errorf("%w: signature is not an object", ErrMalformedSignature)
+	}
+	return signatureMap, nil
+}
 
-	version, ok := signatureMap["v"]
+func signatureVersion(signatureMap map[string]interface{}) (string, error) {
+	rawVersion, exists := signatureMap["v"]
+	if !exists {
+		return "1.0", nil
+	}
+	version, ok := rawVersion.(string)
 	if !ok {
+		return "", fmt.Errorf("%w: version is not a string", ErrMalformedSignature)
+	}
+	return version, nil
+}
+
+func ParseSignature(signature string) (*ParsedSignature, error) {
+	signatureMap, err := decodeSignature(signature)
+	if err != nil {
+		return nil, err
+	}
+	parsed := &ParsedSignature{}
+	parsed.Version, err = signatureVersion(signatureMap)
+	if err != nil {
+		return nil, err
+	}
+	fields, supported := signatureFieldsByVersion[parsed.Version]
+	if !supported {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedVersion, parsed.Version)
+	}
+
+	var ok bool
+	parsed.KeyPairID, ok = signatureMap[fields.keyPairID].(string)
+	if !ok || parsed.KeyPairID == "" {
+		return nil, fmt.Errorf("%w: %w", ErrMalformedSignature, ErrKeyIDNotFound)
+	}
+	timestamp, ok := signatureMap[fields.timestamp].(float64)
+	if !ok || timestamp != math.Trunc(timestamp) || timestamp < 0 || timestamp > maxSignatureTimestamp {
+		return nil, fmt.Errorf("%w: timestamp is missing or not a whole number of seconds", ErrMalformedSignature)
+	}
+	parsed.Timestamp = int64(timestamp)
+	parsed.EncodedSignature, ok = signatureMap[fields.signature].(string)
+	if !ok || parsed.EncodedSignature == "" {
+		return nil, fmt.Errorf("%w: value is missing", ErrMalformedSignature)
+	}
+	parsed.Signature, err = base64.URLEncoding.DecodeString(parsed.EncodedSignature)
+	if err != nil {
+		return nil, fmt.Errorf("%w: value is not base64: %v", ErrMalformedSignature, err)
+	}
+
+	if rawAlgorithm, exists := signatureMap["a"]; exists {
+		if parsed.Algorithm, ok = rawAlgorithm.(string); !ok || parsed.Algorithm == "" {
+			return nil, fmt.Errorf("%w: algorithm is invalid", ErrMalformedSignature)
+		}
+	}
+
+	if parsed.Version != "2.0" {
+		return parsed, nil
+	}
+	if rawHeaders, exists := signatureMap["h"]; exists && rawHeaders != nil {
+		headerList, isList := rawHeaders.([]interface{})
+		if !isList {
+			return nil, fmt.Errorf("%w: header list is invalid", ErrMalformedSignature)
+		}
+		parsed.SignedHeaders = make([]string, 0, len(headerList))
+		for _, rawHeader := range headerList {
+			header, isString := rawHeader.(string)
+			if !isString {
+				return nil, fmt.Errorf("%w: header list is invalid", ErrMalformedSignature)
+			}
+			parsed.SignedHeaders = append(parsed.SignedHeaders, header)
+		}
+	}
+	if rawNonce, exists := signatureMap["n"]; exists && rawNonce != nil {
+		if parsed.Nonce, ok = rawNonce.(string); !ok {
+			return nil, fmt.Errorf("%w: nonce is invalid", ErrMalformedSignature)
+		}
+	}
+	return parsed, nil
+}
+
+// GetSignatureVersion reports "1.0", the legacy format, for any signature whose
+// version cannot be read; ParseSignature rejects those signatures outright.
+func GetSignatureVersion(signature string) string {
+	signatureMap, err := decodeSignature(signature)
+	if err != nil {
+		return "1.0"
+	}
+	var version string
+	version, err = signatureVersion(signatureMap)
+	if err != nil {
 		return "1.0"
 	}
-	return version.(string)
+	return version
 }
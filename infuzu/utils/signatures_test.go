@@ -0,0 +1,89 @@
+package infuzu
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+func encodeSignatureSeed(raw string) string {
+	return base64.URLEncoding.EncodeToString([]byte(raw))
+}
+
+var signatureSeeds = []string{
+	encodeSignatureSeed(`{"signature":"MAYCAQECAQE=","timestamp":1700000000,"id":"abc"}`),
+	encodeSignatureSeed(`{"s":"MAYCAQECAQE=","t":1700000000,"i":"abc","v":"1.2"}`),
+	encodeSignatureSeed(`{"s":"MAYCAQECAQE=","t":1700000000,"i":"abc","v":"2.0","h":["host"],"n":"nonce"}`),
+	encodeSignatureSeed(`{"s":"MAYCAQECAQE=","t":"1700000000","i":"abc","v":"1.2"}`),
+	encodeSignatureSeed(`{"s":1,"t":1700000000,"i":["abc"],"v":1.2}`),
+	encodeSignatureSeed(`{"s":"MAYCAQECAQE=","t":1e300,"i":"abc","v":"2.0","h":[1],"n":{}}`),
+	encodeSignatureSeed(`[]`),
+	encodeSignatureSeed(`null`),
+	"",
+	"not base64",
+}
+
+func FuzzParseSignature(f *testing.F) {
+	for _, seed := range signatureSeeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, signature string) {
+		version := GetSignatureVersion(signature)
+		parsed, err := ParseSignature(signature)
+		if err != nil {
+			if !errors.Is(err, ErrMalformedSignature) && !errors.Is(err, ErrUnsupportedVersion) {
+				t.Fatalf("unexpected error type: %v", err)
+			}
+			if parsed != nil {
+				t.Fatalf("parsed signature returned alongside error %v", err)
+			}
+			return
+		}
+		if _, supported := signatureFieldsByVersion[parsed.Version]; !supported {
+			t.Fatalf("accepted unsupported version %q", parsed.Version)
+		}
+		if parsed.Version != version {
+			t.Fatalf("GetSignatureVersion returned %q, parser returned %q", version, parsed.Version)
+		}
+		if parsed.KeyPairID == "" || parsed.EncodedSignature == "" || parsed.Timestamp < 0 {
+			t.Fatalf("accepted incomplete signature %+v", parsed)
+		}
+	})
+}
+
+func TestParseSignature(t *testing.T) {
+	parsed, err := ParseSignature(signatureSeeds[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Version != "2.0" || parsed.KeyPairID != "abc" || parsed.Timestamp != 1700000000 {
+		t.Fatalf("unexpected parse result %+v", parsed)
+	}
+	if len(parsed.SignedHeaders) != 1 || parsed.SignedHeaders[0] != "host" || parsed.Nonce != "nonce" {
+		t.Fatalf("unexpected request binding %+v", parsed)
+	}
+
+	if _, err = ParseSignature(signatureSeeds[3]); !errors.Is(err, ErrMalformedSignature) {
+		t.Fatalf("expected malformed timestamp error, got %v", err)
+	}
+	if _, err = ParseSignature(encodeSignatureSeed(`{"v":"9.9"}`)); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Fatalf("expected unsupported version error, got %v", err)
+	}
+	for _, signature := range []string{
+		signatureSeeds[4],
+		encodeSignatureSeed(`{"v":null}`),
+		encodeSignatureSeed(`[]`),
+		encodeSignatureSeed(`null`),
+		"not base64",
+	} {
+		if version := GetSignatureVersion(signature); version != "1.0" {
+			t.Fatalf("expected the legacy version for malformed signature %q, got %q", signature, version)
+		}
+		if _, err = ParseSignature(signature); !errors.Is(err, ErrMalformedSignature) {
+			t.Fatalf("expected %q to be rejected as malformed, got %v", signature, err)
+		}
+	}
+	if version := GetSignatureVersion(signatureSeeds[1]); version != "1.2" {
+		t.Fatalf("expected version 1.2, got %q", version)
+	}
+}
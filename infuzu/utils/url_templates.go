@@ -0,0 +1,228 @@
+package infuzu
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type urlTemplateSegment struct {
+	literal   string
+	name      string
+	converter string
+}
+
+type URLTemplate struct {
+	pattern  string
+	segments []urlTemplateSegment
+}
+
+var ErrInvalidURLParameter = errors.New("infuzu/utils/url_templates.go invalid url parameter value")
+
+var (
+	urlTemplatePlaceholder = regexp.MustCompile(`<(?:([A-Za-z_][A-Za-z0-9_]*):)?([A-Za-z_][A-Za-z0-9_]*)>`)
+	slugValue              = regexp.MustCompile(`^[-a-zA-Z0-9_]+$`)
+	parsedURLTemplates     sync.Map
+)
+
+func ParseURLTemplate(pattern string) (*URLTemplate, error) {
+	if cached, ok := parsedURLTemplates.Load(pattern); ok {
+		return cached.(*URLTemplate), nil
+	}
+
+	template := &URLTemplate{pattern: pattern}
+	seen := make(map[string]bool)
+	position := 0
+	for _, match := range urlTemplatePlaceholder.FindAllStringSubmatchIndex(pattern, -1) {
+		literal := pattern[position:match[0]]
+		if strings.ContainsAny(literal, "<>") {
+			return nil, fmt.Errorf("infuzu/utils/url_templates.go malformed placeholder in %q", pattern)
+		}
+		converter := "str"
+		if match[2] >= 0 {
+			converter = pattern[match[2]:match[3]]
+		}
+		if _, ok := urlConverters[converter]; !ok {
+			return nil, fmt.Errorf("infuzu/utils/url_templates.go unknown converter %q in %q", converter, pattern)
+		}
+		name := pattern[match[4]:match[5]]
+		if seen[name] {
+			return nil, fmt.Errorf("infuzu/utils/url_templates.go duplicate parameter %q in %q", name, pattern)
+		}
+		seen[name] = true
+		if literal != "" {
+			template.segments = append(template.segments, urlTemplateSegment{literal: literal})
+		}
+		template.segments = append(template.segments, urlTemplateSegment{name: name, converter: converter})
+		position = match[1]
+	}
+	if literal := pattern[position:]; literal != "" {
+		if strings.ContainsAny(literal, "<>") {
+			return nil, fmt.Errorf("infuzu/utils/url_templates.go malformed placeholder in %q", pattern)
+		}
+		template.segments = append(template.segments, urlTemplateSegment{literal: literal})
+	}
+
+	parsedURLTemplates.Store(pattern, template)
+	return template, nil
+}
+
+func (t *URLTemplate) Pattern() string {
+	return t.pattern
+}
+
+func (t *URLTemplate) Parameters() []string {
+	var names []string
+	for _, segment := range t.segments {
+		if segment.name != "" {
+			names = append(names, segment.name)
+		}
+	}
+	return names
+}
+
+func (t *URLTemplate) Expand(params map[string]interface{}) (string, error) {
+	var builder strings.Builder
+	used := 0
+	for _, segment := range t.segments {
+		if segment.name == "" {
+			builder.WriteString(segment.literal)
+			continue
+		}
+		value, ok := params[segment.name]
+		if !ok {
+			return "", fmt.Errorf("infuzu/utils/url_templates.go missing parameter %q for %q", segment.name, t.pattern)
+		}
+		used++
+		converted, err := urlConverters[segment.converter](value)
+		if err != nil {
+			return "", fmt.Errorf(
+				"%w: parameter %q in %q: %w", ErrInvalidURLParameter, segment.name, t.pattern, err,
+			)
+		}
+		builder.WriteString(url.PathEscape(converted))
+	}
+	if used != len(params) {
+		return "", fmt.Errorf(
+			"infuzu/utils/url_templates.go unexpected parameters %v for %q", t.extraParameters(params), t.pattern,
+		)
+	}
+	return builder.String(), nil
+}
+
+func (t *URLTemplate) extraParameters(params map[string]interface{}) []string {
+	known := make(map[string]bool)
+	for _, name := range t.Parameters() {
+		known[name] = true
+	}
+	var extra []string
+	for name := range params {
+		if !known[name] {
+			extra = append(extra, name)
+		}
+	}
+	sort.Strings(extra)
+	return extra
+}
+
+func ExpandURLTemplate(pattern string, params map[string]interface{}) (string, error) {
+	template, err := ParseURLTemplate(pattern)
+	if err != nil {
+		return "", err
+	}
+	return template.Expand(params)
+}
+
+func BuildURL(baseURL string, pattern string, params map[string]interface{}) (string, error) {
+	path, err := ExpandURLTemplate(pattern, params)
+	if err != nil {
+		return "", err
+	}
+	if strings.HasSuffix(baseURL, "/") && strings.HasPrefix(path, "/") {
+		path = strings.TrimPrefix(path, "/")
+	}
+	return baseURL + path, nil
+}
+
+var urlConverters = map[string]func(value interface{}) (string, error){
+	"str":  convertStrParameter,
+	"int":  convertIntParameter,
+	"uuid": convertUUIDParameter,
+	"slug": convertSlugParameter,
+}
+
+func parameterString(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case *string:
+		if v == nil {
+			return "", fmt.Errorf("value is nil")
+		}
+		return *v, nil
+	case fmt.Stringer:
+		return v.String(), nil
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprintf("%d", v), nil
+	default:
+		return "", fmt.Errorf("unsupported value type %T", value)
+	}
+}
+
+func convertStrParameter(value interface{}) (string, error) {
+	converted, err := parameterString(value)
+	if err != nil {
+		return "", err
+	}
+	if converted == "" {
+		return "", fmt.Errorf("value cannot be empty")
+	}
+	if strings.Contains(converted, "/") {
+		return "", fmt.Errorf("value cannot contain '/'")
+	}
+	if converted == "." || converted == ".." {
+		return "", fmt.Errorf("value cannot be a relative path segment")
+	}
+	return converted, nil
+}
+
+func convertIntParameter(value interface{}) (string, error) {
+	converted, err := parameterString(value)
+	if err != nil {
+		return "", err
+	}
+	number, err := strconv.ParseUint(converted, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("%q is not a non-negative integer", converted)
+	}
+	return strconv.FormatUint(number, 10), nil
+}
+
+func convertUUIDParameter(value interface{}) (string, error) {
+	converted, err := parameterString(value)
+	if err != nil {
+		return "", err
+	}
+	parsed, err := uuid.Parse(converted)
+	if err != nil {
+		return "", fmt.Errorf("%q is not a valid uuid", converted)
+	}
+	return parsed.String(), nil
+}
+
+func convertSlugParameter(value interface{}) (string, error) {
+	converted, err := parameterString(value)
+	if err != nil {
+		return "", err
+	}
+	if !slugValue.MatchString(converted) {
+		return "", fmt.Errorf("%q is not a valid slug", converted)
+	}
+	return converted, nil
+}
@@ -0,0 +1,116 @@
+package infuzu
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type stringerValue struct{}
+
+func (stringerValue) String() string { return "from-stringer" }
+
+func TestParseURLTemplate(t *testing.T) {
+	template, err := ParseURLTemplate("/v1/<slug:org>/items/<int:item_id>/<key>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(template.Parameters(), ","); got != "org,item_id,key" {
+		t.Fatalf("unexpected parameters %q", got)
+	}
+	if again, _ := ParseURLTemplate(template.Pattern()); again != template {
+		t.Fatal("parsed templates should be reused")
+	}
+
+	for _, pattern := range []string{
+		"/v1/<key",
+		"/v1/key>",
+		"/v1/<bad-name>",
+		"/v1/<float:key>",
+		"/v1/<key>/<key>",
+	} {
+		if _, err := ParseURLTemplate(pattern); err == nil {
+			t.Fatalf("expected %q to be rejected", pattern)
+		}
+	}
+}
+
+func TestURLTemplateExpand(t *testing.T) {
+	cases := map[string]struct {
+		pattern  string
+		params   map[string]interface{}
+		expected string
+		invalid  bool
+	}{
+		"escapes values":      {"/keys/<key_id>", map[string]interface{}{"key_id": "a b?c"}, "/keys/a%20b%3Fc", false},
+		"no placeholders":     {"/keys/", map[string]interface{}{}, "/keys/", false},
+		"missing parameter":   {"/keys/<key_id>", map[string]interface{}{}, "", false},
+		"unexpected param":    {"/keys/", map[string]interface{}{"key_id": "x"}, "", false},
+		"empty value":         {"/keys/<key_id>", map[string]interface{}{"key_id": ""}, "", true},
+		"slash in value":      {"/keys/<key_id>", map[string]interface{}{"key_id": "a/b"}, "", true},
+		"current directory":   {"/keys/<key_id>", map[string]interface{}{"key_id": "."}, "", true},
+		"parent directory":    {"/keys/<key_id>", map[string]interface{}{"key_id": ".."}, "", true},
+		"dots inside a value": {"/keys/<key_id>", map[string]interface{}{"key_id": "a..b"}, "/keys/a..b", false},
+	}
+	for name, testCase := range cases {
+		t.Run(name, func(t *testing.T) {
+			expanded, err := ExpandURLTemplate(testCase.pattern, testCase.params)
+			if testCase.expected != "" {
+				if err != nil || expanded != testCase.expected {
+					t.Fatalf("expected %q, got %q, %v", testCase.expected, expanded, err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected an error, got %q", expanded)
+			}
+			if errors.Is(err, ErrInvalidURLParameter) != testCase.invalid {
+				t.Fatalf("unexpected error classification: %v", err)
+			}
+		})
+	}
+}
+
+func TestBuildURL(t *testing.T) {
+	built, err := BuildURL("https://api.infuzu.com/", "/keys/<key_id>", map[string]interface{}{"key_id": "abc"})
+	if err != nil || built != "https://api.infuzu.com/keys/abc" {
+		t.Fatalf("unexpected url %q, %v", built, err)
+	}
+}
+
+func TestURLConverters(t *testing.T) {
+	value := "value"
+	cases := []struct {
+		converter string
+		input     interface{}
+		expected  string
+	}{
+		{"str", "value", "value"},
+		{"str", &value, "value"},
+		{"str", stringerValue{}, "from-stringer"},
+		{"str", 42, "42"},
+		{"str", (*string)(nil), ""},
+		{"str", 1.5, ""},
+		{"int", "0042", "42"},
+		{"int", uint8(7), "7"},
+		{"int", "-1", ""},
+		{"int", "1e3", ""},
+		{"uuid", "6BA7B810-9DAD-11D1-80B4-00C04FD430C8", "6ba7b810-9dad-11d1-80b4-00c04fd430c8"},
+		{"uuid", "not-a-uuid", ""},
+		{"slug", "my-org_1", "my-org_1"},
+		{"slug", "my.org", ""},
+		{"slug", "..", ""},
+	}
+	for _, testCase := range cases {
+		converted, err := urlConverters[testCase.converter](testCase.input)
+		if testCase.expected == "" {
+			if err == nil {
+				t.Fatalf("%s(%v): expected an error, got %q", testCase.converter, testCase.input, converted)
+			}
+			continue
+		}
+		if err != nil || converted != testCase.expected {
+			t.Fatalf("%s(%v): expected %q, got %q, %v", testCase.converter, testCase.input, testCase.expected, converted, err)
+		}
+	}
+}